@@ -0,0 +1,94 @@
+package timewarp
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// SetBounds limits the simulated clock to [min, max]. Now returns min or
+// max while the clock is outside the range. A zero time disables that side.
+// The callbacks registered with OnEndReached run once when the clock
+// reaches max.
+func (tk *TimeKeeper) SetBounds(min, max time.Time) error {
+	if !min.IsZero() && !max.IsZero() && max.Before(min) {
+		return errors.New("timewarp: max bound is before min bound")
+	}
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	if tk.closed {
+		return ErrClosed
+	}
+
+	tk.minTime = min
+	tk.maxTime = max
+	tk.publish()
+	if tk.endTimer != nil {
+		tk.stopTimer(tk.endTimer)
+		tk.endTimer = nil
+	}
+	if !max.IsZero() {
+		tk.endTimer = &SimTimer{tk: tk, when: max, f: tk.endReached}
+		tk.startTimer(tk.endTimer)
+	}
+	return nil
+}
+
+// OnEndReached registers f to be called when the simulated clock reaches
+// the max bound set with SetBounds
+func (tk *TimeKeeper) OnEndReached(f func()) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	tk.endObservers = append(tk.endObservers, f)
+}
+
+// AtBound reports whether Now is currently clamped to one of the bounds
+func (tk *TimeKeeper) AtBound() bool {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	_, clamped := tk.clamp(tk.wrap(tk.now()))
+	return clamped
+}
+
+// Progress returns how far Now has advanced through the loop set with
+// SetLoop, or else between the bounds set with SetBounds, as a fraction in
+// [0, 1]. It returns NaN unless a loop or both bounds are set.
+func (tk *TimeKeeper) Progress() float64 {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	start, end := tk.loopStart, tk.loopEnd
+	if end.IsZero() {
+		start, end = tk.minTime, tk.maxTime
+	}
+	if start.IsZero() || end.IsZero() || !end.After(start) {
+		return math.NaN()
+	}
+
+	fraction := float64(tk.current().Sub(start)) / float64(end.Sub(start))
+	return min(max(fraction, 0), 1)
+}
+
+// clamp limits t to the bounds and reports whether it had to
+func (v *view) clamp(t time.Time) (time.Time, bool) {
+	if !v.minTime.IsZero() && !t.After(v.minTime) {
+		return v.minTime.In(v.location), true
+	}
+	if !v.maxTime.IsZero() && !t.Before(v.maxTime) {
+		return v.maxTime.In(v.location), true
+	}
+	return t, false
+}
+
+// endReached runs the end observers once the max bound is reached
+func (tk *TimeKeeper) endReached(time.Time) {
+	tk.mutex.Lock()
+	tk.endTimer = nil
+	observers := tk.endObservers
+	tk.mutex.Unlock()
+
+	for _, f := range observers {
+		f()
+	}
+}
@@ -0,0 +1,68 @@
+package timewarp
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBounds(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	min, max := start.Add(-time.Hour), start.Add(time.Hour)
+	tk := NewManual(start)
+	if err := tk.SetBounds(min, max); err != nil {
+		t.Fatal(err)
+	}
+	ended := 0
+	tk.OnEndReached(func() { ended++ })
+
+	tk.Advance(2 * time.Hour)
+	if got := tk.Now(); !got.Equal(max) {
+		t.Errorf("Now() past the max bound = %v, want %v", got, max)
+	}
+	if !tk.AtBound() {
+		t.Error("AtBound() = false at the max bound")
+	}
+	tk.Advance(time.Hour)
+	if got := tk.Now(); !got.Equal(max) {
+		t.Errorf("Now() after advancing further = %v, want %v", got, max)
+	}
+	if ended != 1 {
+		t.Errorf("OnEndReached ran %d times, want 1", ended)
+	}
+
+	tk.SetTime(start.Add(-3 * time.Hour))
+	if got := tk.Now(); !got.Equal(min) {
+		t.Errorf("Now() before the min bound = %v, want %v", got, min)
+	}
+
+	if err := tk.SetBounds(max, min); err == nil {
+		t.Error("SetBounds with max before min succeeded")
+	}
+}
+
+func TestProgress(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+	if p := tk.Progress(); !math.IsNaN(p) {
+		t.Errorf("Progress() without bounds = %v, want NaN", p)
+	}
+	if err := tk.SetBounds(start, start.Add(10*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		advance time.Duration
+		want    float64
+	}{
+		{0, 0},
+		{5 * time.Hour, 0.5},
+		{5 * time.Hour, 1},
+		{time.Hour, 1},
+	} {
+		tk.Advance(step.advance)
+		if p := tk.Progress(); p != step.want {
+			t.Errorf("Progress() at %v = %v, want %v", tk.Now(), p, step.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package timewarp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxClosedDays bounds the search of NextOpen through closed days
+const maxClosedDays = 10 * 366
+
+// date is a calendar day independent of any location
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// dateOf returns the calendar day of t in its own location
+func dateOf(t time.Time) date {
+	year, month, day := t.Date()
+	return date{year, month, day}
+}
+
+// BusinessCalendar tells whether the simulated clock of a TimeKeeper falls
+// within opening hours. Working days and holidays are checked in the
+// location of the simulation at the time of the call.
+type BusinessCalendar struct {
+	tk       *TimeKeeper
+	mutex    sync.Mutex
+	open     time.Duration
+	close    time.Duration
+	workdays [7]bool
+	holidays map[date]bool
+}
+
+// NewBusinessCalendar returns a calendar open from open until close, both
+// given as "15:04" and read on the local wall clock. The close time is
+// excluded and must be after open. The working days default to Monday to
+// Friday.
+func NewBusinessCalendar(tk *TimeKeeper, open, close string) (*BusinessCalendar, error) {
+	openAt, err := parseTimeOfDay(open)
+	if err != nil {
+		return nil, err
+	}
+	closeAt, err := parseTimeOfDay(close)
+	if err != nil {
+		return nil, err
+	}
+	if closeAt <= openAt {
+		return nil, errors.New("timewarp: business hours close before they open")
+	}
+
+	c := &BusinessCalendar{
+		tk:       tk,
+		open:     openAt,
+		close:    closeAt,
+		holidays: make(map[date]bool),
+	}
+	for day := time.Monday; day <= time.Friday; day++ {
+		c.workdays[day] = true
+	}
+	return c, nil
+}
+
+// parseTimeOfDay parses "15:04" into the offset from midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: time of day %q, expected 15:04", ErrInvalidTimeFormat, value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// SetWorkdays replaces the days of the week the business opens on
+func (c *BusinessCalendar) SetWorkdays(days ...time.Weekday) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.workdays = [7]bool{}
+	for _, day := range days {
+		c.workdays[day%7] = true
+	}
+}
+
+// AddHoliday closes the business for the whole calendar day of day. The
+// year, month and day are taken as they read in the location of day.
+func (c *BusinessCalendar) AddHoliday(day time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.holidays[dateOf(day)] = true
+}
+
+// IsOpen reports whether the simulated clock is within opening hours
+func (c *BusinessCalendar) IsOpen() bool {
+	return c.IsOpenAt(c.tk.Now())
+}
+
+// IsOpenAt reports whether t, read in the location of the simulation, is
+// within opening hours
+func (c *BusinessCalendar) IsOpenAt(t time.Time) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t = t.In(c.tk.Location())
+	if !c.isWorkday(t) {
+		return false
+	}
+	openAt, closeAt := c.hours(t)
+	return !t.Before(openAt) && t.Before(closeAt)
+}
+
+// NextOpen returns the simulated instant the business next opens at, or the
+// current simulated time if it is open. See NextOpenAfter.
+func (c *BusinessCalendar) NextOpen() time.Time {
+	return c.NextOpenAfter(c.tk.Now())
+}
+
+// NextOpenAfter returns t if the business is open at t, or else the first
+// opening after it, in the location of the simulation. It returns the zero
+// time if no working day comes within ten years.
+func (c *BusinessCalendar) NextOpenAfter(t time.Time) time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t = t.In(c.tk.Location())
+	day := t
+	for range maxClosedDays {
+		if c.isWorkday(day) {
+			openAt, closeAt := c.hours(day)
+			if t.Before(openAt) {
+				return openAt
+			}
+			if t.Before(closeAt) {
+				return t
+			}
+		}
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, day.Location())
+	}
+	return time.Time{}
+}
+
+// isWorkday reports whether the day of t is a working day that is not a
+// holiday. The caller must hold the mutex.
+func (c *BusinessCalendar) isWorkday(t time.Time) bool {
+	return c.workdays[t.Weekday()] && !c.holidays[dateOf(t)]
+}
+
+// hours returns the opening and closing instants on the day of t. The
+// caller must hold the mutex.
+func (c *BusinessCalendar) hours(t time.Time) (time.Time, time.Time) {
+	// built from the wall clock so daylight saving days keep their hours
+	year, month, day := t.Date()
+	at := func(offset time.Duration) time.Time {
+		return time.Date(year, month, day, int(offset/time.Hour), int(offset%time.Hour/time.Minute), 0, 0, t.Location())
+	}
+	return at(c.open), at(c.close)
+}
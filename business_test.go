@@ -0,0 +1,56 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusinessCalendar(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Friday afternoon, the following Monday is a holiday
+	tk := NewManual(time.Date(2024, 3, 29, 16, 30, 0, 0, newYork))
+	c, err := NewBusinessCalendar(tk, "09:00", "17:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.AddHoliday(time.Date(2024, 4, 1, 0, 0, 0, 0, newYork))
+
+	if !c.IsOpen() {
+		t.Error("IsOpen() on Friday 16:30 = false, want true")
+	}
+	if got := c.NextOpen(); !got.Equal(tk.Now()) {
+		t.Errorf("NextOpen() while open = %v, want %v", got, tk.Now())
+	}
+
+	tk.Advance(30 * time.Minute)
+	if c.IsOpen() {
+		t.Error("IsOpen() on Friday 17:00 = true, want false")
+	}
+	tuesday := time.Date(2024, 4, 2, 9, 0, 0, 0, newYork)
+	if got := c.NextOpen(); !got.Equal(tuesday) {
+		t.Errorf("NextOpen() on Friday evening = %v, want %v", got, tuesday)
+	}
+
+	tk.Advance(24 * time.Hour)
+	if c.IsOpen() {
+		t.Error("IsOpen() on Saturday = true, want false")
+	}
+	if c.IsOpenAt(time.Date(2024, 4, 1, 10, 0, 0, 0, newYork)) {
+		t.Error("IsOpenAt() on the holiday = true, want false")
+	}
+	if !c.IsOpenAt(tuesday) {
+		t.Error("IsOpenAt() on Tuesday 09:00 = false, want true")
+	}
+}
+
+func TestNewBusinessCalendarErrors(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	for _, hours := range [][2]string{{"9am", "17:00"}, {"17:00", "09:00"}, {"09:00", "09:00"}} {
+		if _, err := NewBusinessCalendar(tk, hours[0], hours[1]); err == nil {
+			t.Errorf("NewBusinessCalendar(%q, %q) succeeded", hours[0], hours[1])
+		}
+	}
+}
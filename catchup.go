@@ -0,0 +1,39 @@
+package timewarp
+
+import "time"
+
+// CatchUpToReal jumps the simulated clock to the current wall-clock time
+// read from the real clock of the TimeKeeper, keeping the multiplier. A
+// paused clock stays paused at that time. Observers see a TimeSet event.
+func (tk *TimeKeeper) CatchUpToReal() {
+	tk.mutex.Lock()
+	ev := tk.change(TimeSet)
+	tk.setTime(tk.realNow())
+	observers := tk.commit(&ev)
+	tk.mutex.Unlock()
+
+	notify(observers, ev)
+}
+
+// CatchUpToRealSmooth is like CatchUpToReal but slews the simulated clock
+// so it meets the wall clock once over of real time has passed. Afterwards
+// it continues at the multiplier. The clock never runs backward while
+// catching up, so one far ahead takes longer than over. A paused clock or
+// one not running forward jumps like CatchUpToReal.
+func (tk *TimeKeeper) CatchUpToRealSmooth(over time.Duration) {
+	tk.mutex.Lock()
+	ev := tk.change(TimeSet)
+	realTime := tk.realNow()
+	if tk.paused || over <= 0 || tk.multiplier <= 0 {
+		tk.setTime(realTime)
+	} else {
+		// slewTo lands on target plus what the multiplier adds over the
+		// window, aim so that sum is the wall clock at the end of it
+		target := realTime.Add(over - time.Duration(float64(over)*tk.multiplier))
+		tk.slewTo(target, over)
+	}
+	observers := tk.commit(&ev)
+	tk.mutex.Unlock()
+
+	notify(observers, ev)
+}
@@ -0,0 +1,57 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCatchUpToReal(t *testing.T) {
+	real := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)),
+		WithMultiplier(60),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	tk.CatchUpToReal()
+	if got := tk.Now(); !got.Equal(real) {
+		t.Errorf("Now() after CatchUpToReal = %v, want %v", got, real)
+	}
+	if m := tk.Multiplier(); m != 60 {
+		t.Errorf("Multiplier() after CatchUpToReal = %v, want 60", m)
+	}
+}
+
+func TestCatchUpToRealSmooth(t *testing.T) {
+	real := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(real.Add(-time.Hour)),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tk.CatchUpToRealSmooth(10 * time.Second)
+	previous := tk.Now()
+	for i := 0; i < 10; i++ {
+		real = real.Add(time.Second)
+		now := tk.Now()
+		if !now.After(previous) {
+			t.Fatalf("Now() went from %v to %v while catching up", previous, now)
+		}
+		previous = now
+	}
+	if d := tk.Now().Sub(real); d < -time.Millisecond || d > time.Millisecond {
+		t.Errorf("Now() once caught up is %v away from the real clock", d)
+	}
+
+	real = real.Add(time.Minute)
+	if d := tk.Now().Sub(real); d < -time.Millisecond || d > time.Millisecond {
+		t.Errorf("Now() a minute after catching up is %v away from the real clock", d)
+	}
+}
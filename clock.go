@@ -0,0 +1,71 @@
+package timewarp
+
+import "time"
+
+// Clock is the subset of TimeKeeper application code usually depends on. It
+// is satisfied by *TimeKeeper, RealClock and FrozenClock.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+}
+
+var (
+	_ Clock = (*TimeKeeper)(nil)
+	_ Clock = RealClock{}
+	_ Clock = FrozenClock{}
+)
+
+// RealClock is a Clock backed by the standard library without any warping
+type RealClock struct{}
+
+// Now returns the current wall-clock time
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the real time elapsed since t
+func (RealClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// Sleep pauses the current goroutine for d
+func (RealClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// FrozenClock is a Clock whose Now always returns the instant captured by
+// Freeze, so one logical operation sees a single simulated time. Sleep
+// still waits on the TimeKeeper it was taken from.
+type FrozenClock struct {
+	tk  *TimeKeeper
+	now time.Time
+}
+
+// Freeze captures the current simulated time in a FrozenClock. Unlike
+// FreezeAt and quantization it leaves the TimeKeeper running and only
+// affects the callers handed the returned clock.
+func (tk *TimeKeeper) Freeze() FrozenClock {
+	return FrozenClock{tk: tk, now: tk.Now()}
+}
+
+// Now returns the captured simulated time
+func (c FrozenClock) Now() time.Time {
+	return c.now
+}
+
+// Since returns the simulated time elapsed between t and the captured
+// instant
+func (c FrozenClock) Since(t time.Time) time.Duration {
+	return c.now.Sub(t)
+}
+
+// Sleep waits for d of simulated time on the TimeKeeper, or of real time
+// for the zero FrozenClock. The captured instant does not move.
+func (c FrozenClock) Sleep(d time.Duration) {
+	if c.tk == nil {
+		time.Sleep(d)
+		return
+	}
+	c.tk.Sleep(d)
+}
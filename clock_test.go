@@ -0,0 +1,64 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+// elapsedOver measures d of sleep on c
+func elapsedOver(c Clock, d time.Duration) time.Duration {
+	start := c.Now()
+	c.Sleep(d)
+	return c.Since(start)
+}
+
+func TestClockImplementations(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, c := range map[string]Clock{"TimeKeeper": tk, "RealClock": RealClock{}} {
+		if got := elapsedOver(c, 10*time.Millisecond); got < 10*time.Millisecond {
+			t.Errorf("%s: Since after Sleep(10ms) = %v", name, got)
+		}
+	}
+
+	start := time.Now()
+	elapsedOver(tk, time.Minute)
+	if real := time.Since(start); real > 500*time.Millisecond {
+		t.Errorf("TimeKeeper.Sleep(1m) at 3600x took %v of real time", real)
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+	frozen := tk.Freeze()
+
+	tk.Advance(time.Hour)
+	if got := frozen.Now(); !got.Equal(start) {
+		t.Errorf("frozen Now() after the TimeKeeper advanced = %v, want %v", got, start)
+	}
+	if got := tk.Now(); !got.Equal(start.Add(time.Hour)) {
+		t.Errorf("TimeKeeper Now() = %v, want %v", got, start.Add(time.Hour))
+	}
+	if got := frozen.Since(start.Add(-time.Minute)); got != time.Minute {
+		t.Errorf("frozen Since(start - 1m) = %v, want 1m", got)
+	}
+
+	running, err := New("2024-01-01", "00:00", "UTC", 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer running.Close()
+	frozen = running.Freeze()
+	captured := frozen.Now()
+	frozen.Sleep(time.Minute)
+	if got := frozen.Now(); !got.Equal(captured) {
+		t.Errorf("frozen Now() after Sleep = %v, want %v", got, captured)
+	}
+	if !running.Now().After(captured) {
+		t.Error("the running TimeKeeper did not advance past the frozen instant")
+	}
+}
@@ -0,0 +1,57 @@
+package timewarp
+
+import "slices"
+
+// Close stops everything the TimeKeeper runs in the background. Pending
+// timers, tickers and At channels never fire. A resume scheduled with
+// ResumeAfter is dropped and ramps stop at their next step. Auto-sync
+// loops, scheduler runs and event streams return. Goroutines blocked in
+// Sleep or WaitUntil are released. TimeKeepers derived from it are closed
+// as well, and a derived TimeKeeper is detached from its parent. Now keeps
+// reading the clock, but later operations that can fail return ErrClosed
+// and new timers are never armed. Closing twice returns ErrClosed.
+func (tk *TimeKeeper) Close() error {
+	tk.mutex.Lock()
+	if tk.closed {
+		tk.mutex.Unlock()
+		return ErrClosed
+	}
+	tk.closed = true
+	tk.rampGen++
+	for st := range tk.timers {
+		tk.stopTimer(st)
+	}
+	tk.pendingAt = nil
+	tk.endTimer = nil
+	tk.cancelResume()
+	close(tk.closing())
+	children := tk.children
+	tk.children = nil
+	tk.mutex.Unlock()
+
+	for _, child := range children {
+		child.Close()
+	}
+	if parent := tk.parent; parent != nil {
+		parent.mutex.Lock()
+		parent.children = slices.DeleteFunc(parent.children, func(c *TimeKeeper) bool { return c == tk })
+		parent.mutex.Unlock()
+	}
+	return nil
+}
+
+// closing returns the channel closed by Close. The caller must hold the
+// mutex.
+func (tk *TimeKeeper) closing() chan struct{} {
+	if tk.done == nil {
+		tk.done = make(chan struct{})
+	}
+	return tk.done
+}
+
+// doneChan returns the channel closed by Close
+func (tk *TimeKeeper) doneChan() <-chan struct{} {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.closing()
+}
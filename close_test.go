@@ -0,0 +1,68 @@
+package timewarp
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCloseStopsGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	tk, err := NewWithOptions(WithStartTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), WithMultiplier(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.AfterFunc(time.Hour, func() {})
+	ticker := tk.NewTicker(time.Minute)
+	defer ticker.Stop()
+	tk.RampMultiplier(10, time.Hour)
+	tk.ResumeAfter(time.Hour)
+	errs := tk.StartAutoSync(context.Background(), "http://127.0.0.1:1/", time.Hour)
+	sleeping := make(chan struct{})
+	go func() {
+		tk.Sleep(time.Hour)
+		close(sleeping)
+	}()
+	scheduler := NewScheduler(tk)
+	scheduler.Add(Every(time.Hour), func(time.Time) {})
+	ran := make(chan error)
+	go func() { ran <- scheduler.Run(context.Background()) }()
+
+	if err := tk.Close(); err != nil {
+		t.Fatal(err)
+	}
+	<-sleeping
+	if err := <-ran; !errors.Is(err, ErrClosed) {
+		t.Errorf("Scheduler.Run after Close = %v, want ErrClosed", err)
+	}
+	for range errs {
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines left after Close, %d before", n, before)
+	}
+	if err := tk.Close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("second Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestRampMultiplierAfterClose(t *testing.T) {
+	tk, err := NewWithOptions(WithStartTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.Close()
+
+	tk.RampMultiplier(10, 50*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if m := tk.Multiplier(); m != 1 {
+		t.Errorf("Multiplier() after a ramp on a closed TimeKeeper = %v, want 1", m)
+	}
+}
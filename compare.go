@@ -0,0 +1,36 @@
+package timewarp
+
+import "time"
+
+// Offset returns how far the simulated clock of tk is ahead of the one of
+// other, negative if it is behind. Both clocks are read from their
+// published views without taking either mutex, so comparing a TimeKeeper
+// with one derived from it cannot deadlock.
+func (tk *TimeKeeper) Offset(other *TimeKeeper) time.Duration {
+	return tk.Now().Sub(other.Now())
+}
+
+// EqualWithin reports whether the simulated clocks of tk and other agree
+// within tolerance
+func (tk *TimeKeeper) EqualWithin(other *TimeKeeper, tolerance time.Duration) bool {
+	offset := tk.Offset(other)
+	return offset <= tolerance && offset >= -tolerance
+}
+
+// IsAfter reports whether the simulated clock is past t. It is false when
+// the clock reads exactly t.
+func (tk *TimeKeeper) IsAfter(t time.Time) bool {
+	return tk.Now().After(t)
+}
+
+// IsBefore reports whether the simulated clock has not reached t yet. It is
+// false when the clock reads exactly t.
+func (tk *TimeKeeper) IsBefore(t time.Time) bool {
+	return tk.Now().Before(t)
+}
+
+// HasPassed reports whether the simulated clock is past t, like IsAfter a
+// deadline equal to the current reading has not passed yet
+func (tk *TimeKeeper) HasPassed(t time.Time) bool {
+	return tk.IsAfter(t)
+}
@@ -0,0 +1,50 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOffsetAndEqualWithin(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a, b := NewManual(start), NewManual(start.Add(-3*time.Second))
+
+	if got := a.Offset(b); got != 3*time.Second {
+		t.Errorf("a.Offset(b) = %v, want 3s", got)
+	}
+	if got := b.Offset(a); got != -3*time.Second {
+		t.Errorf("b.Offset(a) = %v, want -3s", got)
+	}
+	if !a.EqualWithin(b, 3*time.Second) || !b.EqualWithin(a, 3*time.Second) {
+		t.Error("clocks 3s apart are not equal within 3s")
+	}
+	if a.EqualWithin(b, time.Second) {
+		t.Error("clocks 3s apart are equal within 1s")
+	}
+}
+
+func TestIsAfterIsBefore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tk := NewManual(now)
+
+	tests := []struct {
+		name                     string
+		t                        time.Time
+		after, before, hasPassed bool
+	}{
+		{"earlier", now.Add(-time.Nanosecond), true, false, true},
+		{"equal", now, false, false, false},
+		{"later", now.Add(time.Nanosecond), false, true, false},
+	}
+	for _, tt := range tests {
+		if got := tk.IsAfter(tt.t); got != tt.after {
+			t.Errorf("%s: IsAfter = %v, want %v", tt.name, got, tt.after)
+		}
+		if got := tk.IsBefore(tt.t); got != tt.before {
+			t.Errorf("%s: IsBefore = %v, want %v", tt.name, got, tt.before)
+		}
+		if got := tk.HasPassed(tt.t); got != tt.hasPassed {
+			t.Errorf("%s: HasPassed = %v, want %v", tt.name, got, tt.hasPassed)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package timewarp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// contextKey is the key type for values stored by this package
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying tk
+func NewContext(ctx context.Context, tk *TimeKeeper) context.Context {
+	return context.WithValue(ctx, contextKey{}, tk)
+}
+
+// FromContext returns the TimeKeeper stored in ctx by NewContext, if any
+func FromContext(ctx context.Context) (*TimeKeeper, bool) {
+	tk, ok := ctx.Value(contextKey{}).(*TimeKeeper)
+	return tk, ok && tk != nil
+}
+
+// deadlineContext is cancelled with context.DeadlineExceeded once the
+// simulated clock reaches its deadline
+type deadlineContext struct {
+	context.Context
+
+	mutex sync.Mutex
+	err   error
+}
+
+// Err returns context.DeadlineExceeded once the simulated deadline passed
+func (c *deadlineContext) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	return c.Context.Err()
+}
+
+// WithDeadline returns a copy of parent that is done once the simulated
+// clock reaches simDeadline. Its Err then returns context.DeadlineExceeded.
+// The real wait follows speed changes like AfterFunc. The Deadline method
+// reports the deadline of parent since the real instant is not fixed.
+// Calling cancel releases the timer.
+func (tk *TimeKeeper) WithDeadline(parent context.Context, simDeadline time.Time) (context.Context, context.CancelFunc) {
+	ctx, cancelCause := context.WithCancelCause(parent)
+	c := &deadlineContext{Context: ctx}
+
+	tk.mutex.Lock()
+	st := &SimTimer{
+		tk:   tk,
+		when: simDeadline,
+		f: func(time.Time) {
+			c.mutex.Lock()
+			if c.Context.Err() == nil {
+				c.err = context.DeadlineExceeded
+			}
+			c.mutex.Unlock()
+			cancelCause(context.DeadlineExceeded)
+		},
+	}
+	tk.startTimer(st)
+	tk.mutex.Unlock()
+
+	context.AfterFunc(ctx, func() { st.Stop() })
+	return c, func() { cancelCause(nil) }
+}
@@ -0,0 +1,56 @@
+package timewarp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestContext(t *testing.T) {
+	if tk, ok := FromContext(context.Background()); ok || tk != nil {
+		t.Errorf("FromContext of an empty context = %v, %v", tk, ok)
+	}
+	if _, ok := FromContext(NewContext(context.Background(), nil)); ok {
+		t.Error("FromContext of a nil TimeKeeper reported ok")
+	}
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(NewContext(context.Background(), tk))
+	defer cancel()
+	if got, ok := FromContext(ctx); !ok || got != tk {
+		t.Errorf("FromContext = %v, %v, want the stored TimeKeeper", got, ok)
+	}
+}
+
+func TestWithDeadline(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	start := time.Now()
+	ctx, cancel := tk.WithDeadline(context.Background(), tk.Now().Add(10*time.Second))
+	defer cancel()
+	select {
+	case <-ctx.Done():
+		if real := time.Since(start); real < 80*time.Millisecond || real > 250*time.Millisecond {
+			t.Errorf("10 simulated seconds at 100x took %v, want about 100ms", real)
+		}
+		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			t.Errorf("Err() = %v, want context.DeadlineExceeded", ctx.Err())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the simulated deadline did not cancel the context")
+	}
+
+	ctx, cancel = tk.WithDeadline(context.Background(), tk.Now().Add(time.Hour))
+	cancel()
+	if !errors.Is(ctx.Err(), context.Canceled) {
+		t.Errorf("Err() after cancel = %v, want context.Canceled", ctx.Err())
+	}
+}
@@ -0,0 +1,189 @@
+package timewarp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronSearch bounds the search of CronSchedule.Next for expressions
+// that never match, such as February 30
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+// cronMacros are the shorthands accepted by ParseCron
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes the range and names of a field of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDay    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12, names: []string{
+		"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	cronWeekday = cronField{name: "day of week", min: 0, max: 7, names: []string{
+		"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// CronSchedule is a schedule given by a standard five field cron
+// expression, it can be used with Scheduler.Add or OnSchedule
+type CronSchedule struct {
+	expr                              string
+	minute, hour, day, month, weekday uint64
+	// anyHour, anyDay and anyWeekday record a "*" in their field, which
+	// changes how repeated hours and the day fields are matched
+	anyHour, anyDay, anyWeekday bool
+}
+
+// ParseCron parses a cron expression of five fields: minute, hour, day of
+// month, month and day of week. Each field is a "*", a value, a range "a-b"
+// or a comma separated list of them, optionally followed by a step "/n".
+// Months and days of week may be given by their English three letter names,
+// and Sunday is both 0 and 7. As in most crons a day matches when either
+// day field does, unless one of them is "*". The macros "@hourly",
+// "@daily", "@midnight", "@weekly", "@monthly", "@yearly" and "@annually"
+// are accepted as well.
+func ParseCron(expr string) (*CronSchedule, error) {
+	spec := strings.TrimSpace(expr)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: cron expression %q has %d fields, expected 5", ErrInvalidTimeFormat, expr, len(fields))
+	}
+
+	s := CronSchedule{expr: expr}
+	var err error
+	for i, target := range []*uint64{&s.minute, &s.hour, &s.day, &s.month, &s.weekday} {
+		field := []cronField{cronMinute, cronHour, cronDay, cronMonth, cronWeekday}[i]
+		if *target, err = field.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("%w: cron expression %q: %w", ErrInvalidTimeFormat, expr, err)
+		}
+	}
+	if s.weekday&(1<<7) != 0 {
+		s.weekday |= 1
+	}
+	s.anyHour = fields[1] == "*"
+	s.anyDay = strings.HasPrefix(fields[2], "*")
+	s.anyWeekday = strings.HasPrefix(fields[4], "*")
+	return &s, nil
+}
+
+// parse returns the set of values matched by a field as a bit set
+func (f cronField) parse(value string) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(value, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, f.name)
+			}
+		}
+
+		lo, hi := f.min, f.max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = f.value(from); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = f.value(to); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = f.max
+			}
+			if hi < lo {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangePart, f.name)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// value parses a single number or name of a field
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return i + f.min, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field, expected %d to %d", s, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first minute strictly after t matching the expression on
+// the wall clock of the location of t. A wall-clock time skipped by a
+// daylight saving transition does not match. One repeated by it matches
+// once, except with "*" as the hour where both passes match. Next returns
+// the zero time if nothing matches within five years.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	limit := t.Add(maxCronSearch)
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	for t.Before(limit) {
+		loc := t.Location()
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		case !s.anyHour && repeatedWallClock(t):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchDay reports whether the day of t matches the day fields
+func (s *CronSchedule) matchDay(t time.Time) bool {
+	day := s.day&(1<<uint(t.Day())) != 0
+	weekday := s.weekday&(1<<uint(t.Weekday())) != 0
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// repeatedWallClock reports whether t is the second pass through a
+// wall-clock time repeated when daylight saving time ends
+func repeatedWallClock(t time.Time) bool {
+	first := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	return first.Before(t)
+}
+
+// String returns the expression the schedule was parsed from
+func (s *CronSchedule) String() string {
+	return s.expr
+}
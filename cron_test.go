@@ -0,0 +1,97 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronHourlyOverADay(t *testing.T) {
+	schedule, err := ParseCron("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+
+	var got []time.Time
+	stop := tk.OnSchedule(schedule, func(occurrence time.Time) { got = append(got, occurrence) })
+	defer stop()
+
+	// a simulated day passes in one step as it would at a high multiplier,
+	// every missed hour is still reported
+	tk.Advance(24 * time.Hour)
+
+	if len(got) != 24 {
+		t.Fatalf("hourly cron fired %d times over a day, want 24", len(got))
+	}
+	for i, occurrence := range got {
+		if want := start.Add(time.Duration(i+1) * time.Hour); !occurrence.Equal(want) {
+			t.Errorf("occurrence %d = %v, want %v", i, occurrence, want)
+		}
+	}
+}
+
+func TestCronRunning(t *testing.T) {
+	schedule, err := ParseCron("0 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk, err := NewWithOptions(
+		WithStartTime(time.Date(2024, 1, 1, 9, 59, 50, 0, time.UTC)),
+		WithMultiplier(1000),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	fired := make(chan time.Time, 1)
+	stop := tk.OnSchedule(schedule, func(occurrence time.Time) {
+		select {
+		case fired <- occurrence:
+		default:
+		}
+	})
+	defer stop()
+
+	// ten simulated seconds take 10ms at 1000x
+	select {
+	case got := <-fired:
+		if want := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("occurrence = %v, want %v", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("hourly cron did not fire at 1000x")
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	after := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC) // a Monday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"0 * * * *", time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q) = %v", tt.expr, err)
+			continue
+		}
+		if got := schedule.Next(after); !got.Equal(tt.want) {
+			t.Errorf("%q Next = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* * * * mon-", "*/0 * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded", expr)
+		}
+	}
+}
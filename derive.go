@@ -0,0 +1,53 @@
+package timewarp
+
+import "time"
+
+// Derive returns a child TimeKeeper whose clock runs multiplier times as
+// fast as the simulated clock of tk. Both start from the current simulated
+// time. The child reads the parent clock wherever another TimeKeeper reads
+// the real clock, so speed changes, pauses and syncs of the parent carry
+// over to the child. The effective speed of the child relative to real time
+// is the product of both multipliers. It panics if multiplier is NaN or
+// infinite.
+func (tk *TimeKeeper) Derive(multiplier float64) *TimeKeeper {
+	if validateMultiplier(multiplier, false) != nil {
+		panic("timewarp: invalid multiplier for Derive")
+	}
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	child, _ := NewWithOptions(
+		WithStartTime(tk.current()),
+		WithMultiplier(multiplier),
+		WithLocation(tk.location),
+		WithNowFunc(tk.Now),
+	)
+	child.parent = tk
+	tk.children = append(tk.children, child)
+	return child
+}
+
+// Shift returns a child TimeKeeper whose clock always reads d ahead of the
+// simulated clock of tk, behind for a negative d. It is a derived
+// TimeKeeper running at the speed of the parent. Speed changes, jumps,
+// pauses and syncs of the parent carry over immediately, and the offset
+// stays fixed until the child itself is changed.
+func (tk *TimeKeeper) Shift(d time.Duration) *TimeKeeper {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	now := tk.current()
+	child, _ := NewWithOptions(
+		WithStartTime(now.Add(d)),
+		WithLocation(tk.location),
+		WithNowFunc(tk.Now),
+	)
+	// anchor on the very reading the start was computed from
+	child.startRealTime = now
+	child.initialRealTime = now
+	child.publish()
+	child.parent = tk
+	tk.children = append(tk.children, child)
+	return child
+}
@@ -0,0 +1,99 @@
+package timewarp
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDeriveRunsFaster(t *testing.T) {
+	real := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return real }
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent, err := NewWithOptions(WithStartTime(start), WithNowFunc(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := parent.Derive(2)
+
+	real = real.Add(time.Minute)
+	if got := parent.Now().Sub(start); got != time.Minute {
+		t.Errorf("parent advanced %v, want 1m", got)
+	}
+	if got := child.Now().Sub(start); got != 2*time.Minute {
+		t.Errorf("child advanced %v, want 2m", got)
+	}
+
+	parent.SetMultiplier(3)
+	real = real.Add(time.Minute)
+	if got := child.Now().Sub(start); got != 8*time.Minute {
+		t.Errorf("child advanced %v after the parent sped up, want 8m", got)
+	}
+}
+
+func TestDeriveInvalidMultiplier(t *testing.T) {
+	parent := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer func() {
+		if recover() == nil {
+			t.Error("Derive(NaN) did not panic")
+		}
+	}()
+	parent.Derive(math.NaN())
+}
+
+func TestCloseDetachesDerived(t *testing.T) {
+	parent := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	for range 100 {
+		parent.Derive(2).Close()
+	}
+	kept := parent.Derive(2)
+	defer kept.Close()
+
+	parent.mutex.Lock()
+	n := len(parent.children)
+	parent.mutex.Unlock()
+	if n != 1 {
+		t.Errorf("parent holds %d children after closing all but one, want 1", n)
+	}
+}
+
+func TestShiftKeepsOffset(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	parent, err := NewWithOptions(
+		WithStartTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+		WithMultiplier(10),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := parent.Shift(time.Hour)
+
+	check := func(when string) {
+		t.Helper()
+		if d := child.Now().Sub(parent.Now()); d != time.Hour {
+			t.Errorf("child - parent %s = %v, want 1h", when, d)
+		}
+	}
+	check("after Shift")
+	real = real.Add(time.Second)
+	check("at 10x")
+	parent.SetMultiplier(1000)
+	check("after SetMultiplier")
+	real = real.Add(time.Second)
+	check("at 1000x")
+}
+
+func TestCloseDetachesShifted(t *testing.T) {
+	parent := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	for range 100 {
+		parent.Shift(time.Hour).Close()
+	}
+
+	parent.mutex.Lock()
+	n := len(parent.children)
+	parent.mutex.Unlock()
+	if n != 0 {
+		t.Errorf("parent holds %d children after closing every shifted one, want 0", n)
+	}
+}
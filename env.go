@@ -0,0 +1,66 @@
+package timewarp
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Environment variable names read by FromEnv and written by Env. Each is
+// prefixed with the prefix passed to them.
+const (
+	EnvStartTime  = "START_TIME"
+	EnvMultiplier = "MULTIPLIER"
+	EnvTimezone   = "TIMEZONE"
+)
+
+// FromEnv initializes a TimeKeeper from environment variables. With the
+// prefix "TW_" the start time is read from TW_START_TIME in RFC 3339 format
+// and is required. TW_MULTIPLIER defaults to 1 and TW_TIMEZONE to UTC.
+func FromEnv(prefix string) (*TimeKeeper, error) {
+	value, ok := os.LookupEnv(prefix + EnvStartTime)
+	if !ok || value == "" {
+		return nil, fmt.Errorf("timewarp: %s%s is not set", prefix, EnvStartTime)
+	}
+	start, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s%s: %w", ErrInvalidTimeFormat, prefix, EnvStartTime, err)
+	}
+
+	multiplier := 1.0
+	if value := os.Getenv(prefix + EnvMultiplier); value != "" {
+		multiplier, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s%s: %w", ErrInvalidMultiplier, prefix, EnvMultiplier, err)
+		}
+	}
+
+	location := time.UTC
+	if value := os.Getenv(prefix + EnvTimezone); value != "" {
+		location, err = loadLocation(value)
+		if err != nil {
+			return nil, fmt.Errorf("timewarp: %s%s: %w", prefix, EnvTimezone, err)
+		}
+	}
+
+	return NewWithOptions(
+		WithStartTime(start),
+		WithMultiplier(multiplier),
+		WithLocation(location),
+	)
+}
+
+// Env returns the current state as the variables read by FromEnv. The start
+// time is the current simulated time, so a child process started with them
+// continues the simulation.
+func (tk *TimeKeeper) Env(prefix string) map[string]string {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	return map[string]string{
+		prefix + EnvStartTime:  tk.current().Format(time.RFC3339Nano),
+		prefix + EnvMultiplier: strconv.FormatFloat(tk.multiplier, 'g', -1, 64),
+		prefix + EnvTimezone:   zoneName(tk.location),
+	}
+}
@@ -0,0 +1,75 @@
+package timewarp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnvRoundTrip(t *testing.T) {
+	tk, err := New("2024-01-01", "12:00", "Asia/Tokyo", 2.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.Pause()
+	for k, v := range tk.Env("TW_") {
+		t.Setenv(k, v)
+	}
+
+	restored, err := FromEnv("TW_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := restored.Multiplier(); m != 2.5 {
+		t.Errorf("Multiplier() from env = %v, want 2.5", m)
+	}
+	if loc := restored.Location().String(); loc != "Asia/Tokyo" {
+		t.Errorf("Location() from env = %q, want Asia/Tokyo", loc)
+	}
+	if d := restored.Now().Sub(tk.Now()); d < 0 || d > time.Second {
+		t.Errorf("Now() from env is %v away from the original", d)
+	}
+}
+
+func TestFromEnvErrors(t *testing.T) {
+	t.Setenv("TW_START_TIME", "")
+	if _, err := FromEnv("TW_"); err == nil {
+		t.Error("FromEnv without a start time succeeded")
+	}
+
+	t.Setenv("TW_START_TIME", "2024-01-01T00:00:00Z")
+	t.Setenv("TW_MULTIPLIER", "fast")
+	if _, err := FromEnv("TW_"); !errors.Is(err, ErrInvalidMultiplier) {
+		t.Errorf("FromEnv with a bad multiplier = %v, want ErrInvalidMultiplier", err)
+	}
+
+	t.Setenv("TW_MULTIPLIER", "")
+	t.Setenv("TW_TIMEZONE", "Nowhere/Special")
+	if _, err := FromEnv("TW_"); !errors.Is(err, ErrInvalidTimezone) {
+		t.Errorf("FromEnv with a bad zone = %v, want ErrInvalidTimezone", err)
+	}
+
+	t.Setenv("TW_TIMEZONE", "")
+	tk, err := FromEnv("TW_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tk.Multiplier() != 1 || tk.Location() != time.UTC {
+		t.Errorf("FromEnv defaults = %v, want 1x in UTC", tk)
+	}
+}
+
+func TestEnvFixedZone(t *testing.T) {
+	tk := NewManual(time.Date(2024, 6, 1, 12, 0, 0, 0, time.FixedZone("", -5*60*60)))
+	for k, v := range tk.Env("TW_") {
+		t.Setenv(k, v)
+	}
+
+	restored, err := FromEnv("TW_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, offset := restored.Now().Zone(); offset != -5*60*60 {
+		t.Errorf("offset from env = %d, want %d", offset, -5*60*60)
+	}
+}
@@ -0,0 +1,64 @@
+package timewarp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidMultiplier is returned for a multiplier that is NaN, infinite
+// or, when positive multipliers are required, zero or below
+var ErrInvalidMultiplier = errors.New("timewarp: invalid multiplier")
+
+// ErrInvalidTimezone is returned for a time zone name that is empty or
+// unknown to the time zone database
+var ErrInvalidTimezone = errors.New("timewarp: invalid time zone")
+
+// ErrInvalidTimeFormat is returned for a start time or time of day that
+// does not match any accepted layout
+var ErrInvalidTimeFormat = errors.New("timewarp: invalid time format")
+
+// ErrSyncFailed wraps the cause of a sync that could not fetch or read the
+// remote time, such as a network failure, an unexpected status or a bad
+// payload. Mistakes in the sync url are reported as ErrInvalidSyncURL
+// instead.
+var ErrSyncFailed = errors.New("timewarp: sync failed")
+
+// ErrMultiplierOutOfRange is returned by SetMultiplierChecked for a
+// multiplier outside the range set with SetMultiplierRange
+var ErrMultiplierOutOfRange = errors.New("timewarp: multiplier out of range")
+
+// ErrClosed is returned by operations on a TimeKeeper after Close
+var ErrClosed = errors.New("timewarp: TimeKeeper is closed")
+
+// ErrNotModified is returned by a TimeSource whose time has not changed
+// since the previous fetch. SynchronizeFrom treats it as a successful sync
+// that leaves the clock untouched.
+var ErrNotModified = errors.New("timewarp: time source not modified")
+
+// ErrUnreachable is returned by RealTimeFor for a simulated instant the
+// clock will not reach at its current speed
+var ErrUnreachable = errors.New("timewarp: simulated instant is not reached")
+
+// ErrInvalidSyncURL is returned by Synchronize for a url that is not an
+// absolute http or https URL or whose host is not allowed
+var ErrInvalidSyncURL = errors.New("timewarp: invalid sync url")
+
+// ErrManual is returned by RampMultiplier on a TimeKeeper in manual mode,
+// where no real time passes to ease the multiplier over
+var ErrManual = errors.New("timewarp: TimeKeeper is in manual mode")
+
+// ErrSlowClient is returned by Hub.Serve for a client dropped because it
+// did not keep up with the messages broadcast to it
+var ErrSlowClient = errors.New("timewarp: hub client too slow")
+
+// validateMultiplier checks that a multiplier can drive the clock
+func validateMultiplier(multiplier float64, requirePositive bool) error {
+	if math.IsNaN(multiplier) || math.IsInf(multiplier, 0) {
+		return fmt.Errorf("%w: %v", ErrInvalidMultiplier, multiplier)
+	}
+	if requirePositive && multiplier <= 0 {
+		return fmt.Errorf("%w: %v is not positive", ErrInvalidMultiplier, multiplier)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package timewarp
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestErrorCategories(t *testing.T) {
+	badPayload := serveJSON(t, `{"simulated_time":"not a time"}`)
+	sentinels := []error{
+		ErrInvalidMultiplier, ErrInvalidTimezone, ErrInvalidTimeFormat,
+		ErrSyncFailed, ErrInvalidSyncURL,
+	}
+
+	tests := []struct {
+		name string
+		err  func() error
+		want error
+	}{
+		{"NaN multiplier", func() error {
+			_, err := New("2024-01-01", "00:00", "UTC", math.NaN())
+			return err
+		}, ErrInvalidMultiplier},
+		{"unknown zone", func() error {
+			_, err := New("2024-01-01", "00:00", "Mars/Olympus_Mons", 1)
+			return err
+		}, ErrInvalidTimezone},
+		{"bad date", func() error {
+			_, err := New("01/02/2024", "00:00", "UTC", 1)
+			return err
+		}, ErrInvalidTimeFormat},
+		{"unreachable server", func() error {
+			return NewManual(time.Now()).Synchronize("http://127.0.0.1:1/")
+		}, ErrSyncFailed},
+		{"bad payload", func() error {
+			return NewManual(time.Now()).Synchronize(badPayload.URL)
+		}, ErrSyncFailed},
+		{"bad url", func() error {
+			return NewManual(time.Now()).Synchronize("file:///etc/passwd")
+		}, ErrInvalidSyncURL},
+	}
+	for _, tt := range tests {
+		err := tt.err()
+		for _, sentinel := range sentinels {
+			if got, want := errors.Is(err, sentinel), sentinel == tt.want; got != want {
+				t.Errorf("%s: errors.Is(%v, %v) = %v, want %v", tt.name, err, sentinel, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package timewarp_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kaneetz/timewarp"
+)
+
+func ExampleTimeKeeper_Add() {
+	tk := timewarp.NewManual(time.Date(2016, 12, 31, 23, 0, 0, 0, time.UTC))
+	tk.SetLeapSeconds(true)
+
+	// two hours of simulated time include the leap second of 2016
+	later := tk.Add(tk.Now(), 2*time.Hour)
+	fmt.Println(later.Format(time.TimeOnly))
+	fmt.Println(tk.Sub(later, tk.Now()))
+	// Output:
+	// 00:59:59
+	// 2h0m0s
+}
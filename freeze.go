@@ -0,0 +1,63 @@
+package timewarp
+
+import "time"
+
+// FreezeAt jumps the simulated clock to t and pauses it there in one step,
+// observers see a single Paused event
+func (tk *TimeKeeper) FreezeAt(t time.Time) {
+	tk.mutex.Lock()
+	ev := tk.change(Paused)
+	tk.cancelResume()
+	if !tk.paused {
+		tk.pause()
+	}
+	tk.setTime(t)
+	observers := tk.commit(&ev)
+	tk.mutex.Unlock()
+
+	notify(observers, ev)
+}
+
+// ResumeAfter schedules a Resume once d of real time has passed. It
+// replaces a resume scheduled before and can be cancelled with
+// CancelResume. A pending resume is dropped by Close.
+func (tk *TimeKeeper) ResumeAfter(d time.Duration) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	tk.cancelResume()
+	if tk.closed {
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		tk.mutex.Lock()
+		current := tk.resumeTimer == timer
+		if current {
+			tk.resumeTimer = nil
+		}
+		tk.mutex.Unlock()
+		if current {
+			tk.Resume()
+		}
+	})
+	tk.resumeTimer = timer
+}
+
+// CancelResume cancels a resume scheduled with ResumeAfter. It returns
+// false if none was pending.
+func (tk *TimeKeeper) CancelResume() bool {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.cancelResume()
+}
+
+// cancelResume stops the pending resume, the caller must hold the mutex
+func (tk *TimeKeeper) cancelResume() bool {
+	if tk.resumeTimer == nil {
+		return false
+	}
+	tk.resumeTimer.Stop()
+	tk.resumeTimer = nil
+	return true
+}
@@ -0,0 +1,54 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFreezeAtResumeAfter(t *testing.T) {
+	tk, err := NewWithOptions(WithMultiplier(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	noon := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tk.FreezeAt(noon)
+	tk.ResumeAfter(50 * time.Millisecond)
+
+	time.Sleep(20 * time.Millisecond)
+	if got := tk.Now(); !got.Equal(noon) {
+		t.Errorf("Now() during the freeze = %v, want %v", got, noon)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for tk.IsPaused() {
+		if time.Now().After(deadline) {
+			t.Fatal("the clock did not resume")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := tk.Now(); !got.After(noon) {
+		t.Errorf("Now() after the resume = %v, want after %v", got, noon)
+	}
+}
+
+func TestCancelResume(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer tk.Close()
+
+	tk.FreezeAt(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	tk.ResumeAfter(20 * time.Millisecond)
+	if !tk.CancelResume() {
+		t.Fatal("CancelResume() = false with a resume pending")
+	}
+	if tk.CancelResume() {
+		t.Error("CancelResume() = true with no resume pending")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if !tk.IsPaused() {
+		t.Error("the clock resumed after CancelResume")
+	}
+}
@@ -0,0 +1,98 @@
+package timewarp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// timeResponse is the payload served by Handler. It matches what
+// Synchronize expects by default.
+type timeResponse struct {
+	SimulatedTime string  `json:"simulated_time"`
+	Multiplier    float64 `json:"multiplier"`
+}
+
+// response reads the current time and multiplier in one go
+func (tk *TimeKeeper) response() timeResponse {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return timeResponse{
+		SimulatedTime: tk.current().Format(time.RFC3339Nano),
+		Multiplier:    tk.multiplier,
+	}
+}
+
+// Handler returns an HTTP handler serving the current simulated time and
+// multiplier as JSON, other TimeKeepers can Synchronize from it
+func (tk *TimeKeeper) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tk.response())
+	})
+}
+
+// defaultSSEInterval is used by SSEHandler for non-positive intervals
+const defaultSSEInterval = time.Second
+
+// SSEHandler returns an HTTP handler streaming the simulated time as
+// server-sent events. An event is sent on connection, every interval and on
+// every change to the speed or the time, each carrying the same JSON as
+// Handler. Streams end when the TimeKeeper is closed.
+func (tk *TimeKeeper) SSEHandler(interval time.Duration) http.Handler {
+	if interval <= 0 {
+		interval = defaultSSEInterval
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		changes := make(chan struct{}, 1)
+		unsubscribe := tk.subscribe(func(ChangeEvent) {
+			select {
+			case changes <- struct{}{}:
+			default:
+			}
+		})
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		closing := tk.doneChan()
+
+		for {
+			data, err := json.Marshal(tk.response())
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-closing:
+				return
+			case <-ticker.C:
+			case <-changes:
+			}
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package timewarp
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerRoundTrip(t *testing.T) {
+	source, err := New("2030-06-01", "12:00", "UTC", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(source.Handler())
+	defer srv.Close()
+
+	tk, err := New("2024-01-01", "00:00", "Europe/Paris", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if m := tk.Multiplier(); m != 60 {
+		t.Errorf("Multiplier() after sync = %v, want 60", m)
+	}
+	if d := tk.Now().Sub(source.Now()); d < -time.Second || d > time.Second {
+		t.Errorf("synchronized clock is %v away from the source", d)
+	}
+
+	resp, err := http.Post(srv.URL, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestSSEHandler(t *testing.T) {
+	tk, err := New("2030-06-01", "12:00", "UTC", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(tk.SSEHandler(10 * time.Millisecond))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var events []timeResponse
+	for len(events) < 2 && scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var ev timeResponse
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			t.Fatalf("event %q: %v", data, err)
+		}
+		events = append(events, ev)
+	}
+	if len(events) < 2 {
+		t.Fatalf("read %d events, want 2: %v", len(events), scanner.Err())
+	}
+	for _, ev := range events {
+		if ev.Multiplier != 60 || !strings.HasPrefix(ev.SimulatedTime, "2030-06-01T12:") {
+			t.Errorf("event = %+v, want the time and multiplier of the clock", ev)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package timewarp
+
+// defaultHistorySize is the number of events kept by History unless
+// changed with SetHistorySize
+const defaultHistorySize = 64
+
+// History returns the most recent control events, oldest first. Each
+// records the simulated and real time of a multiplier change, time set,
+// sync, pause, resume or reset. At most the number of events set with
+// SetHistorySize are kept.
+func (tk *TimeKeeper) History() []ChangeEvent {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	history := make([]ChangeEvent, 0, len(tk.history))
+	history = append(history, tk.history[tk.historyNext:]...)
+	return append(history, tk.history[:tk.historyNext]...)
+}
+
+// SetHistorySize changes how many events History keeps. The most recent
+// ones are retained when shrinking and zero or below disables the history.
+func (tk *TimeKeeper) SetHistorySize(n int) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	n = max(n, 0)
+	history := append(tk.history[tk.historyNext:len(tk.history):len(tk.history)], tk.history[:tk.historyNext]...)
+	if len(history) > n {
+		history = history[len(history)-n:]
+	}
+	tk.history = history
+	tk.historyNext = 0
+	tk.historySize = n
+}
+
+// record appends ev to the history, overwriting the oldest event once it is
+// full. The caller must hold the mutex.
+func (tk *TimeKeeper) record(ev ChangeEvent) {
+	if tk.historySize == 0 {
+		return
+	}
+	if len(tk.history) < tk.historySize {
+		tk.history = append(tk.history, ev)
+		return
+	}
+	tk.history[tk.historyNext] = ev
+	tk.historyNext = (tk.historyNext + 1) % tk.historySize
+}
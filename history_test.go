@@ -0,0 +1,56 @@
+package timewarp
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHistoryKeepsLastEvents(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tk.SetHistorySize(3)
+
+	for i := 1; i <= 5; i++ {
+		tk.SetMultiplier(float64(i))
+	}
+
+	history := tk.History()
+	if len(history) != 3 {
+		t.Fatalf("History() kept %d events, want 3", len(history))
+	}
+	for i, ev := range history {
+		if want := float64(i + 3); ev.NewMultiplier != want {
+			t.Errorf("History()[%d].NewMultiplier = %v, want %v", i, ev.NewMultiplier, want)
+		}
+	}
+
+	tk.SetHistorySize(2)
+	if history := tk.History(); len(history) != 2 || history[1].NewMultiplier != 5 {
+		t.Errorf("History() after shrinking = %+v", history)
+	}
+}
+
+func TestHistoryConcurrent(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tk.SetHistorySize(8)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tk.SetMultiplier(float64(j%10 + 1))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if n := len(tk.History()); n > 8 {
+					t.Errorf("History() returned %d events, want at most 8", n)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,273 @@
+package timewarp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultHubInterval is used by NewHub for non-positive intervals
+const defaultHubInterval = time.Second
+
+// hubBuffer is the number of messages queued for a client before it is
+// dropped as too slow
+const hubBuffer = 16
+
+// Conn is a message based connection such as a WebSocket. It keeps the Hub
+// independent of any WebSocket library: a thin adapter over the connection
+// of gorilla/websocket or nhooyr.io/websocket reading and writing text
+// messages is enough. Read and Write are each called from one goroutine at
+// a time, and Close must unblock a pending Read.
+type Conn interface {
+	Read(ctx context.Context) ([]byte, error)
+	Write(ctx context.Context, data []byte) error
+	Close() error
+}
+
+// Hub broadcasts the simulated clock of a TimeKeeper to connected clients
+// as JSON messages. A "time" message with the simulated time and the
+// multiplier like Handler is sent on connection and every interval. An
+// "event" message is pushed for every change reported to OnChange. Clients
+// allowed to control the clock may send commands.
+type Hub struct {
+	tk       *TimeKeeper
+	interval time.Duration
+	mutex    sync.Mutex
+	clients  map[*hubClient]struct{}
+}
+
+// hubClient is a connection registered with a Hub
+type hubClient struct {
+	send    chan []byte
+	dropped chan struct{}
+}
+
+// hubTime is the periodic message of a Hub
+type hubTime struct {
+	Type string `json:"type"`
+	timeResponse
+}
+
+// hubEvent is the message pushed by a Hub for a ChangeEvent
+type hubEvent struct {
+	Type          string    `json:"type"`
+	Kind          string    `json:"kind"`
+	OldMultiplier float64   `json:"old_multiplier"`
+	NewMultiplier float64   `json:"new_multiplier"`
+	OldTime       time.Time `json:"old_time"`
+	NewTime       time.Time `json:"new_time"`
+	At            time.Time `json:"at"`
+}
+
+// hubError is sent back to a client whose command failed
+type hubError struct {
+	Type  string `json:"type"`
+	Error string `json:"error"`
+}
+
+// hubCommand is a control message sent by a client, Command is one of
+// "set_multiplier", which requires Multiplier, "pause" and "resume"
+type hubCommand struct {
+	Command    string   `json:"command"`
+	Multiplier *float64 `json:"multiplier"`
+}
+
+// NewHub returns a hub for the clock of tk sending the time every interval
+// of real time, or every second for non-positive intervals. Messages are
+// only broadcast while Run is active.
+func NewHub(tk *TimeKeeper, interval time.Duration) *Hub {
+	if interval <= 0 {
+		interval = defaultHubInterval
+	}
+	return &Hub{
+		tk:       tk,
+		interval: interval,
+		clients:  make(map[*hubClient]struct{}),
+	}
+}
+
+// Run broadcasts the time every interval and every change event to the
+// connected clients. It returns ctx.Err() once ctx is done, or ErrClosed
+// once the TimeKeeper is closed.
+func (h *Hub) Run(ctx context.Context) error {
+	unsubscribe := h.tk.subscribe(func(ev ChangeEvent) {
+		h.broadcast(encodeHubMessage(hubEvent{
+			Type:          "event",
+			Kind:          ev.Kind.String(),
+			OldMultiplier: ev.OldMultiplier,
+			NewMultiplier: ev.NewMultiplier,
+			OldTime:       ev.OldTime,
+			NewTime:       ev.NewTime,
+			At:            ev.At,
+		}))
+	})
+	defer unsubscribe()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	closing := h.tk.doneChan()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-closing:
+			return ErrClosed
+		case <-ticker.C:
+			h.broadcast(h.timeMessage())
+		}
+	}
+}
+
+// Serve registers conn with the hub and serves it until ctx is done, the
+// connection fails, the client falls too far behind or the TimeKeeper is
+// closed. A client falling behind makes it return ErrSlowClient. The
+// connection is closed on return. Control lets the client pause, resume and
+// set the multiplier and must only be granted to authorized clients. The
+// commands of other clients are answered with an "error" message.
+func (h *Hub) Serve(ctx context.Context, conn Conn, control bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c := &hubClient{
+		send:    make(chan []byte, hubBuffer),
+		dropped: make(chan struct{}),
+	}
+	c.send <- h.timeMessage()
+	h.mutex.Lock()
+	h.clients[c] = struct{}{}
+	h.mutex.Unlock()
+	defer h.drop(c)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		errs <- h.write(ctx, conn, c)
+	}()
+	go func() {
+		defer wg.Done()
+		errs <- h.read(ctx, conn, c, control)
+	}()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-h.tk.doneChan():
+		err = ErrClosed
+	case <-c.dropped:
+		err = ErrSlowClient
+	}
+	cancel()
+	conn.Close()
+	wg.Wait()
+	return err
+}
+
+// write sends the messages queued for c until ctx is done
+func (h *Hub) write(ctx context.Context, conn Conn, c *hubClient) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-c.send:
+			if err := conn.Write(ctx, msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// read runs the commands received from c until the connection fails
+func (h *Hub) read(ctx context.Context, conn Conn, c *hubClient, control bool) error {
+	for {
+		data, err := conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+		if err := h.command(data, control); err != nil {
+			select {
+			case c.send <- encodeHubMessage(hubError{Type: "error", Error: err.Error()}):
+			default:
+			}
+		}
+	}
+}
+
+// command applies a control message. The resulting change reaches the
+// clients as an event.
+func (h *Hub) command(data []byte, control bool) error {
+	var cmd hubCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return fmt.Errorf("timewarp: invalid command: %w", err)
+	}
+	if !control {
+		return fmt.Errorf("timewarp: command %q is not allowed", cmd.Command)
+	}
+
+	switch cmd.Command {
+	case "set_multiplier":
+		if cmd.Multiplier == nil {
+			return fmt.Errorf("timewarp: command %q requires a multiplier", cmd.Command)
+		}
+		return h.tk.SetMultiplierChecked(*cmd.Multiplier)
+	case "pause":
+		h.tk.Pause()
+	case "resume":
+		h.tk.Resume()
+	default:
+		return fmt.Errorf("timewarp: unknown command %q", cmd.Command)
+	}
+	return nil
+}
+
+// broadcast queues msg for every client. Clients whose queue is full are
+// dropped.
+func (h *Hub) broadcast(msg []byte) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for c := range h.clients {
+		select {
+		case c.send <- msg:
+		default:
+			delete(h.clients, c)
+			close(c.dropped)
+		}
+	}
+}
+
+// drop unregisters c unless it was already dropped
+func (h *Hub) drop(c *hubClient) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.clients[c]; ok {
+		delete(h.clients, c)
+		close(c.dropped)
+	}
+}
+
+// Clients returns the number of connected clients
+func (h *Hub) Clients() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.clients)
+}
+
+// timeMessage encodes the current time and multiplier
+func (h *Hub) timeMessage() []byte {
+	return encodeHubMessage(hubTime{Type: "time", timeResponse: h.tk.response()})
+}
+
+// encodeHubMessage encodes a message of the hub. Messages only hold plain
+// values so encoding cannot fail.
+func encodeHubMessage(msg any) []byte {
+	data, _ := json.Marshal(msg)
+	return data
+}
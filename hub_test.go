@@ -0,0 +1,167 @@
+package timewarp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// fakeConn is a Conn fed and drained by a test
+type fakeConn struct {
+	in     chan []byte
+	out    chan []byte
+	closed chan struct{}
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{
+		in:     make(chan []byte, 8),
+		out:    make(chan []byte, 64),
+		closed: make(chan struct{}),
+	}
+}
+
+func (c *fakeConn) Read(ctx context.Context) ([]byte, error) {
+	select {
+	case data := <-c.in:
+		return data, nil
+	case <-c.closed:
+		return nil, io.EOF
+	}
+}
+
+func (c *fakeConn) Write(ctx context.Context, data []byte) error {
+	c.out <- data
+	return nil
+}
+
+func (c *fakeConn) Close() error {
+	select {
+	case <-c.closed:
+	default:
+		close(c.closed)
+	}
+	return nil
+}
+
+// hubMessage is the union of the messages sent by a Hub
+type hubMessage struct {
+	Type          string  `json:"type"`
+	SimulatedTime string  `json:"simulated_time"`
+	Multiplier    float64 `json:"multiplier"`
+	Kind          string  `json:"kind"`
+	NewMultiplier float64 `json:"new_multiplier"`
+	Error         string  `json:"error"`
+}
+
+// next returns the next message of the given type sent to c
+func (c *fakeConn) next(t *testing.T, typ string) hubMessage {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case data := <-c.out:
+			var msg hubMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("invalid message %s: %v", data, err)
+			}
+			if msg.Type == typ {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("no %q message received", typ)
+		}
+	}
+}
+
+func TestHub(t *testing.T) {
+	tk, err := NewWithOptions(WithStartTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := NewHub(tk, 20*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	viewer, controller := newFakeConn(), newFakeConn()
+	served := make(chan error, 2)
+	go func() { served <- hub.Serve(ctx, viewer, false) }()
+	go func() { served <- hub.Serve(ctx, controller, true) }()
+
+	if msg := viewer.next(t, "time"); msg.Multiplier != 1 || msg.SimulatedTime == "" {
+		t.Errorf("first message = %+v", msg)
+	}
+	controller.next(t, "time")
+
+	viewer.in <- []byte(`{"command":"pause"}`)
+	if msg := viewer.next(t, "error"); msg.Error == "" {
+		t.Error("command of a viewer was not rejected")
+	}
+	if tk.IsPaused() {
+		t.Error("command of a viewer paused the clock")
+	}
+
+	controller.in <- []byte(`{"command":"set_multiplier"}`)
+	if msg := controller.next(t, "error"); msg.Error == "" {
+		t.Error("set_multiplier without a multiplier was not rejected")
+	}
+	if m := tk.Multiplier(); m != 1 {
+		t.Errorf("set_multiplier without a multiplier set it to %v", m)
+	}
+
+	controller.in <- []byte(`{"command":"set_multiplier","multiplier":3}`)
+	if msg := viewer.next(t, "event"); msg.Kind != "MultiplierChanged" || msg.NewMultiplier != 3 {
+		t.Errorf("event = %+v, want MultiplierChanged to 3", msg)
+	}
+	if msg := viewer.next(t, "time"); msg.Multiplier != 3 {
+		t.Errorf("periodic time message = %+v, want multiplier 3", msg)
+	}
+
+	viewer.Close()
+	if err := <-served; err != io.EOF {
+		t.Errorf("Serve after the connection closed = %v, want io.EOF", err)
+	}
+	tk.Close()
+	if err := <-served; !errors.Is(err, ErrClosed) {
+		t.Errorf("Serve after Close = %v, want ErrClosed", err)
+	}
+	if n := hub.Clients(); n != 0 {
+		t.Errorf("%d clients left registered", n)
+	}
+}
+
+func TestHubDropsSlowClient(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	hub := NewHub(tk, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	conn := newFakeConn()
+	conn.out = make(chan []byte) // never drained
+	served := make(chan error, 1)
+	go func() { served <- hub.Serve(ctx, conn, false) }()
+	for hub.Clients() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < 2*hubBuffer; i++ {
+		tk.SetMultiplier(float64(i + 2))
+	}
+	go func() {
+		for range conn.out {
+		}
+	}()
+	select {
+	case err := <-served:
+		if !errors.Is(err, ErrSlowClient) {
+			t.Errorf("Serve for a slow client = %v, want ErrSlowClient", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("slow client was not dropped")
+	}
+}
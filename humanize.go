@@ -0,0 +1,52 @@
+package timewarp
+
+import (
+	"strconv"
+	"time"
+)
+
+// humanUnits are the units used by Humanize, largest first
+var humanUnits = []struct {
+	name string
+	d    time.Duration
+}{
+	{"year", 365 * 24 * time.Hour},
+	{"month", 30 * 24 * time.Hour},
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+}
+
+// Humanize describes t relative to the simulated now, like "in 3 hours" or
+// "2 days ago". The offset is rounded to the second and then truncated to a
+// whole number of the largest unit it spans, with months of 30 days and
+// years of 365 days. Offsets below half a second read "now".
+func (tk *TimeKeeper) Humanize(t time.Time) string {
+	return humanize(t.Sub(tk.Now()))
+}
+
+// humanize describes an offset from now
+func humanize(d time.Duration) string {
+	d = d.Round(time.Second)
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+
+	for _, unit := range humanUnits {
+		if abs < unit.d {
+			continue
+		}
+		n := int64(abs / unit.d)
+		text := strconv.FormatInt(n, 10) + " " + unit.name
+		if n != 1 {
+			text += "s"
+		}
+		if d < 0 {
+			return text + " ago"
+		}
+		return "in " + text
+	}
+	return "now"
+}
@@ -0,0 +1,30 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanize(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tk := NewManual(now)
+
+	tests := []struct {
+		offset time.Duration
+		want   string
+	}{
+		{0, "now"},
+		{400 * time.Millisecond, "now"},
+		{time.Second, "in 1 second"},
+		{-90 * time.Second, "1 minute ago"},
+		{3*time.Hour + 59*time.Minute, "in 3 hours"},
+		{-2 * 24 * time.Hour, "2 days ago"},
+		{45 * 24 * time.Hour, "in 1 month"},
+		{-800 * 24 * time.Hour, "2 years ago"},
+	}
+	for _, tt := range tests {
+		if got := tk.Humanize(now.Add(tt.offset)); got != tt.want {
+			t.Errorf("Humanize(now + %v) = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package timewarp
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// jitter adds bounded random noise to the readings of the simulated clock
+type jitter struct {
+	max   time.Duration
+	mutex sync.Mutex
+	rand  *rand.Rand
+}
+
+// apply shifts t by a random offset within [-max, max]
+func (j *jitter) apply(t time.Time) time.Time {
+	j.mutex.Lock()
+	offset := time.Duration(j.rand.Int63n(int64(2*j.max)+1)) - j.max
+	j.mutex.Unlock()
+	return t.Add(offset)
+}
+
+// SetJitter makes Now return the simulated time shifted by a random offset
+// within [-max, max], to exercise code that must tolerate an unsteady
+// clock. The offsets are seeded randomly, see SetJitterSeed for
+// reproducible runs. Timers and anchors keep using the exact clock. Bounds
+// still apply to the shifted value, and in monotonic mode Now holds its
+// last value rather than step backward. A max of zero or below disables
+// jitter.
+func (tk *TimeKeeper) SetJitter(max time.Duration) {
+	tk.SetJitterSeed(max, time.Now().UnixNano())
+}
+
+// SetJitterSeed is like SetJitter but draws the offsets from a source
+// seeded with seed, so the same sequence of readings is jittered the same
+// way on every run.
+func (tk *TimeKeeper) SetJitterSeed(max time.Duration, seed int64) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	tk.jitter = nil
+	if max > 0 {
+		tk.jitter = &jitter{max: max, rand: rand.New(rand.NewSource(seed))}
+	}
+	tk.publish()
+}
@@ -0,0 +1,65 @@
+package timewarp
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+	if got := tk.Now(); !got.Equal(start) {
+		t.Errorf("Now() without jitter = %v, want %v", got, start)
+	}
+
+	const max = 10 * time.Millisecond
+	tk.SetJitter(max)
+	deviated := false
+	for i := 0; i < 100; i++ {
+		d := tk.Now().Sub(start)
+		if d < -max || d > max {
+			t.Fatalf("jittered Now() is %v off, more than %v", d, max)
+		}
+		deviated = deviated || d != 0
+	}
+	if !deviated {
+		t.Error("jittered Now() never deviated")
+	}
+
+	tk.SetJitter(0)
+	if got := tk.Now(); !got.Equal(start) {
+		t.Errorf("Now() after disabling jitter = %v, want %v", got, start)
+	}
+}
+
+func TestJitterSeed(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	readings := func() []time.Time {
+		tk := NewManual(start)
+		tk.SetJitterSeed(time.Second, 42)
+		var ts []time.Time
+		for i := 0; i < 10; i++ {
+			ts = append(ts, tk.Now())
+		}
+		return ts
+	}
+	if a, b := readings(), readings(); !slices.EqualFunc(a, b, time.Time.Equal) {
+		t.Errorf("the same seed jittered %v and %v", a, b)
+	}
+}
+
+func TestJitterMonotonic(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tk.SetMonotonic(true)
+	tk.SetJitter(time.Second)
+	last := tk.Now()
+	for i := 0; i < 100; i++ {
+		tk.Advance(10 * time.Millisecond)
+		now := tk.Now()
+		if now.Before(last) {
+			t.Fatalf("jittered Now() went backward from %v to %v", last, now)
+		}
+		last = now
+	}
+}
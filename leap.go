@@ -0,0 +1,93 @@
+package timewarp
+
+import (
+	"sort"
+	"time"
+)
+
+// leapSeconds lists the UTC instants right after each inserted leap second.
+// The leap second 23:59:60 precedes each of them.
+var leapSeconds = []time.Time{
+	time.Date(1972, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1973, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1974, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1975, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1976, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1977, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1978, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1979, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1981, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1982, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1983, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1985, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1988, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1991, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1992, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1993, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1994, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1996, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1997, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2012, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2015, 7, 1, 0, 0, 0, 0, time.UTC),
+	time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// leapsBefore returns the number of leap seconds inserted before t
+func leapsBefore(t time.Time) int {
+	return sort.Search(len(leapSeconds), func(i int) bool {
+		return leapSeconds[i].After(t)
+	})
+}
+
+// leapCount maps a civil instant onto a continuous count like TAI where
+// each leap second is a regular second. The instant the civil clock holds
+// at during a leap second maps to the start of the leap second.
+func leapCount(t time.Time) time.Time {
+	n := leapsBefore(t)
+	if n < len(leapSeconds) && t.Equal(leapSeconds[n].Add(-1)) {
+		return leapSeconds[n].Add(time.Duration(n) * time.Second)
+	}
+	return t.Add(time.Duration(n) * time.Second)
+}
+
+// leapAdd returns the civil instant reached after elapsed SI time from
+// start. Leap seconds inserted in between consume a second each without
+// moving the civil clock, which holds at the last nanosecond of the day
+// meanwhile.
+func leapAdd(start time.Time, elapsed time.Duration) time.Time {
+	target := leapCount(start).Add(elapsed)
+	n := sort.Search(len(leapSeconds), func(i int) bool {
+		return leapSeconds[i].Add(time.Duration(i+1) * time.Second).After(target)
+	})
+	civil := target.Add(-time.Duration(n) * time.Second)
+	if n < len(leapSeconds) && !civil.Before(leapSeconds[n]) {
+		civil = leapSeconds[n].Add(-1)
+	}
+	return civil
+}
+
+// leapSub returns the SI time elapsed from the civil instant start to t,
+// counting the leap seconds inserted in between
+func leapSub(t, start time.Time) time.Duration {
+	return leapCount(t).Sub(leapCount(start))
+}
+
+// SetLeapSeconds makes the simulated clock account for the leap seconds
+// inserted into UTC since 1972. The simulation then advances in SI seconds,
+// so across an insertion the civil reading of Now lags by a second, holding
+// just before midnight during the leap second. Duration then reports the
+// elapsed civil time. It is off by default, like the time package which
+// ignores leap seconds.
+func (tk *TimeKeeper) SetLeapSeconds(enabled bool) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	tk.rebase()
+	tk.leapSeconds = enabled
+	tk.changed()
+}
@@ -0,0 +1,43 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeapSeconds(t *testing.T) {
+	midnight := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(midnight.Add(-10 * time.Second))
+	tk.SetLeapSeconds(true)
+
+	tk.Advance(10 * time.Second)
+	if got, want := tk.Now(), midnight.Add(-1); !got.Equal(want) {
+		t.Errorf("Now() during the leap second = %v, want %v", got, want)
+	}
+	tk.Advance(time.Second)
+	if got := tk.Now(); !got.Equal(midnight) {
+		t.Errorf("Now() after the leap second = %v, want %v", got, midnight)
+	}
+	tk.Advance(time.Minute)
+	if got, want := tk.Now(), midnight.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("Now() a minute later = %v, want %v", got, want)
+	}
+
+	// without leap seconds the same advance reaches midnight
+	tk = NewManual(midnight.Add(-10 * time.Second))
+	tk.Advance(10 * time.Second)
+	if got := tk.Now(); !got.Equal(midnight) {
+		t.Errorf("Now() without leap seconds = %v, want %v", got, midnight)
+	}
+}
+
+func TestLeapSub(t *testing.T) {
+	from := time.Date(2016, 12, 31, 23, 0, 0, 0, time.UTC)
+	to := time.Date(2017, 1, 1, 1, 0, 0, 0, time.UTC)
+	if got := leapSub(to, from); got != 2*time.Hour+time.Second {
+		t.Errorf("leapSub across the 2016 leap second = %v, want 2h0m1s", got)
+	}
+	if got := leapSub(from, from.Add(-time.Hour)); got != time.Hour {
+		t.Errorf("leapSub without a leap second = %v, want 1h", got)
+	}
+}
@@ -0,0 +1,74 @@
+package timewarp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// SetMultiplierRange constrains the multiplier accepted by SetMultiplier
+// and RampMultiplier to [min, max]. Values outside are clamped, or rejected
+// by SetMultiplierChecked. The current multiplier is left as is until the
+// next change. A range containing zero still allows freezing the clock, and
+// a min of zero or above rules out running it backward. Passing -Inf and
+// +Inf removes the constraint. Syncs are not constrained since the remote
+// source is the authority on the speed.
+func (tk *TimeKeeper) SetMultiplierRange(min, max float64) error {
+	if math.IsNaN(min) || math.IsNaN(max) {
+		return fmt.Errorf("%w: NaN bound", ErrInvalidMultiplier)
+	}
+	if max < min {
+		return errors.New("timewarp: max multiplier is below min multiplier")
+	}
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	if tk.closed {
+		return ErrClosed
+	}
+
+	tk.minMultiplier = min
+	tk.maxMultiplier = max
+	tk.hasMultiplierRange = !math.IsInf(min, -1) || !math.IsInf(max, 1)
+	return nil
+}
+
+// clampMultiplier brings multiplier into the configured range. The caller
+// must hold the mutex.
+func (tk *TimeKeeper) clampMultiplier(multiplier float64) float64 {
+	if !tk.hasMultiplierRange {
+		return multiplier
+	}
+	return min(max(multiplier, tk.minMultiplier), tk.maxMultiplier)
+}
+
+// SetMaxAcceleration limits how fast the multiplier may change to perSecond
+// per real second. SetMultiplier then eases into the new value like
+// RampMultiplier instead of snapping, and shorter ramps are stretched.
+// EffectiveRate then changes, in the steps of a ramp, no faster than the
+// limit, apart from syncs, resets and manual mode. Zero removes the limit.
+func (tk *TimeKeeper) SetMaxAcceleration(perSecond float64) error {
+	if math.IsNaN(perSecond) || math.IsInf(perSecond, 0) || perSecond < 0 {
+		return fmt.Errorf("%w: acceleration limit %v", ErrInvalidMultiplier, perSecond)
+	}
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	if tk.closed {
+		return ErrClosed
+	}
+
+	tk.maxAcceleration = perSecond
+	return nil
+}
+
+// accelerationTime returns the real time needed to reach target from the
+// current multiplier within the acceleration limit, or zero without a
+// limit. The caller must hold the mutex.
+func (tk *TimeKeeper) accelerationTime(target float64) time.Duration {
+	if tk.maxAcceleration == 0 {
+		return 0
+	}
+	return time.Duration(math.Abs(target-tk.multiplier) / tk.maxAcceleration * float64(time.Second))
+}
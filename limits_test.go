@@ -0,0 +1,64 @@
+package timewarp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMultiplierRange(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err := tk.SetMultiplierRange(0, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	tk.SetMultiplier(1000)
+	if m := tk.Multiplier(); m != 100 {
+		t.Errorf("Multiplier() after SetMultiplier(1000) = %v, want 100", m)
+	}
+	tk.SetMultiplier(-5)
+	if m := tk.Multiplier(); m != 0 {
+		t.Errorf("Multiplier() after SetMultiplier(-5) = %v, want 0", m)
+	}
+	if err := tk.SetMultiplierChecked(1000); !errors.Is(err, ErrMultiplierOutOfRange) {
+		t.Errorf("SetMultiplierChecked(1000) = %v, want ErrMultiplierOutOfRange", err)
+	}
+	if err := tk.SetMultiplierRange(10, 1); err == nil {
+		t.Error("SetMultiplierRange with max below min succeeded")
+	}
+}
+
+func TestMaxAcceleration(t *testing.T) {
+	tk, err := NewWithOptions(WithStartTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	if err := tk.SetMaxAcceleration(330); err != nil {
+		t.Fatal(err)
+	}
+
+	// from 1x to 100x at 330x per second takes 300ms
+	started := time.Now()
+	tk.SetMultiplier(100)
+	previous := tk.EffectiveRate()
+	for previous != 100 {
+		if time.Since(started) > 2*time.Second {
+			t.Fatalf("EffectiveRate() = %v after 2s, want 100", previous)
+		}
+		time.Sleep(30 * time.Millisecond)
+		// a late step of the ramp only lags behind the limit
+		rate, elapsed := tk.EffectiveRate(), time.Since(started)
+		if limit := 1 + 330*elapsed.Seconds(); rate < previous || rate > limit {
+			t.Errorf("EffectiveRate() went from %v to %v after %v, the limit allows %v", previous, rate, elapsed, limit)
+		}
+		previous = rate
+	}
+	if elapsed := time.Since(started); elapsed < 270*time.Millisecond || elapsed > time.Second {
+		t.Errorf("reaching 100x took %v, want about 300ms", elapsed)
+	}
+
+	if err := tk.SetMaxAcceleration(-1); !errors.Is(err, ErrInvalidMultiplier) {
+		t.Errorf("SetMaxAcceleration(-1) = %v, want ErrInvalidMultiplier", err)
+	}
+}
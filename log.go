@@ -0,0 +1,46 @@
+package timewarp
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SetLogger makes the TimeKeeper log every change reported to OnChange and
+// every failed sync to logger at debug level. Passing nil disables logging,
+// which is the default.
+func (tk *TimeKeeper) SetLogger(logger *slog.Logger) {
+	var unsubscribe func()
+	if logger != nil {
+		unsubscribe = tk.subscribe(func(ev ChangeEvent) {
+			logger.LogAttrs(context.Background(), slog.LevelDebug, "timewarp: "+ev.Kind.String(),
+				slog.Float64("old_multiplier", ev.OldMultiplier),
+				slog.Float64("new_multiplier", ev.NewMultiplier),
+				slog.Time("old_time", ev.OldTime),
+				slog.Time("new_time", ev.NewTime),
+			)
+		})
+	}
+
+	tk.mutex.Lock()
+	previous := tk.unsubscribeLogger
+	tk.logger = logger
+	tk.unsubscribeLogger = unsubscribe
+	tk.mutex.Unlock()
+
+	if previous != nil {
+		previous()
+	}
+}
+
+// logSyncFailure logs a failed sync
+func (tk *TimeKeeper) logSyncFailure(err error) {
+	tk.mutex.Lock()
+	logger := tk.logger
+	tk.mutex.Unlock()
+
+	if logger != nil {
+		logger.LogAttrs(context.Background(), slog.LevelDebug, "timewarp: sync failed",
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,67 @@
+package timewarp
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordHandler keeps the records logged through it
+type recordHandler struct {
+	mutex   sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordHandler) WithGroup(string) slog.Handler { return h }
+
+func TestSetLogger(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	h := &recordHandler{}
+	tk.SetLogger(slog.New(h))
+
+	tk.SetMultiplier(5)
+
+	h.mutex.Lock()
+	records := h.records
+	h.mutex.Unlock()
+	if len(records) != 1 {
+		t.Fatalf("SetMultiplier logged %d records, want 1", len(records))
+	}
+	r := records[0]
+	if r.Level != slog.LevelDebug || r.Message != "timewarp: MultiplierChanged" {
+		t.Errorf("record = %v %q, want DEBUG %q", r.Level, r.Message, "timewarp: MultiplierChanged")
+	}
+	attrs := make(map[string]slog.Value)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value
+		return true
+	})
+	if got := attrs["old_multiplier"].Float64(); got != 1 {
+		t.Errorf("old_multiplier = %v, want 1", got)
+	}
+	if got := attrs["new_multiplier"].Float64(); got != 5 {
+		t.Errorf("new_multiplier = %v, want 5", got)
+	}
+
+	tk.SetLogger(nil)
+	tk.SetMultiplier(2)
+	h.mutex.Lock()
+	n := len(h.records)
+	h.mutex.Unlock()
+	if n != 1 {
+		t.Errorf("after SetLogger(nil) %d records were logged, want 1", n)
+	}
+}
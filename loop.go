@@ -0,0 +1,49 @@
+package timewarp
+
+import (
+	"errors"
+	"time"
+)
+
+// SetLoop makes the simulated clock wrap around from end back to start. Now
+// always returns a value in [start, end) while the underlying clock keeps
+// advancing at the current speed, so Pause and SetMultiplier act on the
+// loop position as usual.
+func (tk *TimeKeeper) SetLoop(start, end time.Time) error {
+	if !end.After(start) {
+		return errors.New("timewarp: loop end must be after loop start")
+	}
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	if tk.closed {
+		return ErrClosed
+	}
+	tk.loopStart = start
+	tk.loopEnd = end
+	tk.publish()
+	return nil
+}
+
+// ClearLoop disables looping set with SetLoop
+func (tk *TimeKeeper) ClearLoop() {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	tk.loopStart = time.Time{}
+	tk.loopEnd = time.Time{}
+	tk.publish()
+}
+
+// wrap maps t into the loop range
+func (v *view) wrap(t time.Time) time.Time {
+	if v.loopEnd.IsZero() {
+		return t
+	}
+
+	span := v.loopEnd.Sub(v.loopStart)
+	offset := t.Sub(v.loopStart) % span
+	if offset < 0 {
+		offset += span
+	}
+	return v.loopStart.Add(offset).In(v.location)
+}
@@ -0,0 +1,41 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoop(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	tk := NewManual(start)
+	if err := tk.SetLoop(start, end); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []struct {
+		advance time.Duration
+		want    time.Duration
+	}{
+		{30 * time.Minute, 30 * time.Minute},
+		{30 * time.Minute, 0},
+		{75 * time.Minute, 15 * time.Minute},
+	} {
+		tk.Advance(step.advance)
+		now := tk.Now()
+		if now.Before(start) || !now.Before(end) {
+			t.Errorf("Now() = %v is outside the loop", now)
+		}
+		if want := start.Add(step.want); !now.Equal(want) {
+			t.Errorf("Now() = %v, want %v", now, want)
+		}
+	}
+
+	tk.ClearLoop()
+	if got, want := tk.Now(), start.Add(135*time.Minute); !got.Equal(want) {
+		t.Errorf("Now() after ClearLoop = %v, want %v", got, want)
+	}
+	if err := tk.SetLoop(end, start); err == nil {
+		t.Error("SetLoop with end before start succeeded")
+	}
+}
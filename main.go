@@ -1,102 +1,974 @@
 package timewarp
 
 import (
-	"encoding/json"
-	"io"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// TimeKeeper manages the simulated time
+// TimeKeeper manages the simulated time. Simulated time runs at multiplier
+// times the real speed. A multiplier of zero freezes it and a negative one
+// runs it backward. The real anchor is always read from the real clock.
+// With time.Now elapsed real time is measured on its monotonic reading, so
+// stepping the wall clock, by NTP or by hand, does not move the simulation.
+// Whether time spent in system sleep counts as elapsed depends on the
+// monotonic clock of the platform.
 type TimeKeeper struct {
+	view
+	published          atomic.Pointer[view]
+	lastNow            atomic.Pointer[time.Time]
+	initialSimTime     time.Time
+	initialMultiplier  float64
+	initialRealTime    time.Time
+	pausedRealTime     time.Time
+	totalPaused        time.Duration
+	observers          []observer
+	observerSeq        uint64
+	endTimer           *SimTimer
+	endObservers       []func()
+	rampGen            uint64
+	rampTarget         float64
+	rampTargetGen      uint64
+	requirePositive    bool
+	metrics            Metrics
+	parent             *TimeKeeper
+	children           []*TimeKeeper
+	timers             map[*SimTimer]struct{}
+	timerSeq           uint64
+	firing             int
+	pendingAt          map[<-chan time.Time]*SimTimer
+	httpClient         *http.Client
+	syncConfig         SyncConfig
+	lastSync           time.Time
+	lastSyncRTT        time.Duration
+	lastSyncUpdated    bool
+	syncETags          map[string]string
+	syncHosts          []string
+	logger             *slog.Logger
+	unsubscribeLogger  func()
+	resumeTimer        *time.Timer
+	minMultiplier      float64
+	maxMultiplier      float64
+	hasMultiplierRange bool
+	maxAcceleration    float64
+	closed             bool
+	history            []ChangeEvent
+	historyNext        int
+	historySize        int
+	timeline           []Segment
+	timelineSize       int
+	timelineRamp       uint64
+	done               chan struct{}
+	mutex              sync.Mutex
+}
+
+// view holds everything needed to read the simulated clock. It is a plain
+// value, so a copy can be read without holding the mutex. The TimeKeeper
+// publishes a fresh copy after every change so Now does not need to lock.
+type view struct {
 	startRealTime time.Time
 	startSimTime  time.Time
 	multiplier    float64
-	mutex         sync.Mutex
+	location      *time.Location
+	paused        bool
+	pausedAt      time.Time
+	manual        bool
+	slewRate      float64
+	slewUntil     time.Time
+	monotonic     bool
+	minTime       time.Time
+	maxTime       time.Time
+	loopStart     time.Time
+	loopEnd       time.Time
+	nowFunc       func() time.Time
+	timeGauge     Gauge
+	jitter        *jitter
+	leapSeconds   bool
+	quantum       time.Duration
+	quantumBase   time.Time
+}
+
+// publish makes the current view visible to Now and records it in the
+// timeline. The caller must hold the mutex and call it after changing any
+// field of the view.
+func (tk *TimeKeeper) publish() {
+	v := tk.view
+	tk.published.Store(&v)
+	tk.recordTimeline()
+}
+
+// loadView returns the published view, or a copy taken under the mutex for
+// a TimeKeeper that never published one. The caller must not hold the
+// mutex.
+func (tk *TimeKeeper) loadView() *view {
+	if v := tk.published.Load(); v != nil {
+		return v
+	}
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	v := tk.view
+	return &v
+}
+
+// startLayouts are the layouts accepted by New in the order they are tried
+var startLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02",
 }
 
-// New initializes a new TimeKeeper instance
+// New initializes a new TimeKeeper instance. The start date and time are
+// joined with a space. They may be given as RFC 3339, "2006-01-02 15:04:05"
+// with optional fractional seconds, "2006-01-02 15:04" or a date only
+// starting at midnight. A wall-clock start skipped or repeated by a
+// daylight saving transition is resolved like time.ParseInLocation does.
 func New(startDate, startTime, timeZone string, multiplier float64) (*TimeKeeper, error) {
-	location, err := time.LoadLocation(timeZone)
+	location, err := loadLocation(timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	startSimTime, err := parseStart(strings.TrimSpace(startDate+" "+startTime), location)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithOptions(
+		WithStartTime(startSimTime),
+		WithMultiplier(multiplier),
+		WithLocation(location),
+	)
+}
+
+// NewFromNow initializes a TimeKeeper starting at the current wall-clock
+// time in the given time zone
+func NewFromNow(multiplier float64, timeZone string) (*TimeKeeper, error) {
+	location, err := loadLocation(timeZone)
 	if err != nil {
 		return nil, err
 	}
 
-	startSimTime, err := time.ParseInLocation("2006-01-02 15:04", startDate+" "+startTime, location)
+	return NewWithOptions(
+		WithStartNow(),
+		WithMultiplier(multiplier),
+		WithLocation(location),
+	)
+}
+
+// NewFromUnix initializes a TimeKeeper starting at sec seconds since the
+// epoch, shown in the given time zone
+func NewFromUnix(sec int64, timeZone string, multiplier float64) (*TimeKeeper, error) {
+	return newFromInstant(time.Unix(sec, 0), timeZone, multiplier)
+}
+
+// NewFromUnixMilli initializes a TimeKeeper starting at msec milliseconds
+// since the epoch, shown in the given time zone
+func NewFromUnixMilli(msec int64, timeZone string, multiplier float64) (*TimeKeeper, error) {
+	return newFromInstant(time.UnixMilli(msec), timeZone, multiplier)
+}
+
+// newFromInstant initializes a TimeKeeper starting at start in the given
+// time zone
+func newFromInstant(start time.Time, timeZone string, multiplier float64) (*TimeKeeper, error) {
+	location, err := loadLocation(timeZone)
 	if err != nil {
 		return nil, err
 	}
 
-	startRealTime := time.Now()
+	return NewWithOptions(
+		WithStartTime(start.In(location)),
+		WithMultiplier(multiplier),
+		WithLocation(location),
+	)
+}
 
-	return &TimeKeeper{
-		startRealTime: startRealTime,
-		startSimTime:  startSimTime,
-		multiplier:    multiplier,
-	}, nil
+// parseStart parses a start time with the first matching layout
+func parseStart(value string, location *time.Location) (time.Time, error) {
+	for _, layout := range startLayouts {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: start %q, accepted formats are %s",
+		ErrInvalidTimeFormat, value, strings.Join(startLayouts, ", "))
 }
 
-// Now returns the current simulated time
+// loadLocation loads the named time zone. Unlike time.LoadLocation it
+// rejects an empty name, which would silently mean UTC, and accepts a fixed
+// offset such as "+02:00". Errors wrap ErrInvalidTimezone and suggest the
+// closest valid name.
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, fmt.Errorf(`%w: empty name, use "UTC", "Local" or an IANA name such as "Europe/Paris"`, ErrInvalidTimezone)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		if location, ok := parseFixedZone(name); ok {
+			return location, nil
+		}
+		if suggestion := suggestTimezone(name); suggestion != "" {
+			return nil, fmt.Errorf("%w: %w, did you mean %q?", ErrInvalidTimezone, err, suggestion)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTimezone, err)
+	}
+	return location, nil
+}
+
+// Now returns the current simulated time in the location of the simulation.
+// The clock advances in absolute time, so across a daylight saving
+// transition the wall-clock reading jumps by the shift. One simulated hour
+// after 01:30 on a spring forward night in New York reads 03:30. Now does
+// not lock, except to draw an offset when SetJitter is enabled. It does not
+// allocate, except in monotonic mode where every new highest value is
+// stored on the heap.
 func (tk *TimeKeeper) Now() time.Time {
+	v := tk.published.Load()
+	if v == nil {
+		tk.mutex.Lock()
+		v = &tk.view
+		defer tk.mutex.Unlock()
+	}
+
+	now := v.read(&tk.lastNow)
+	if v.timeGauge != nil {
+		v.timeGauge.Set(float64(now.UnixNano()) / 1e9)
+	}
+	return now
+}
+
+// current returns the simulated time as handed out to callers. The caller
+// must hold the mutex.
+func (tk *TimeKeeper) current() time.Time {
+	return tk.view.read(&tk.lastNow)
+}
+
+// read returns the simulated time as handed out to callers. It is
+// quantized, wrapped into the loop, jittered and kept within the bounds. In
+// monotonic mode it never goes below a value stored in last before.
+func (v *view) read(last *atomic.Pointer[time.Time]) time.Time {
+	now := v.wrap(v.quantize(v.simAt(v.realNow())))
+	if v.jitter != nil {
+		now = v.jitter.apply(now)
+	}
+	now, _ = v.clamp(now)
+	if !v.monotonic {
+		return now
+	}
+
+	for {
+		prev := last.Load()
+		if prev != nil && now.Before(*prev) {
+			return prev.In(v.location)
+		}
+		stored := now
+		if last.CompareAndSwap(prev, &stored) {
+			return now
+		}
+	}
+}
+
+// SetMonotonic enables or disables monotonic mode. In monotonic mode Now
+// never returns a value earlier than one it returned before. It holds the
+// last value until the clock catches up after a jump backward, so with a
+// negative multiplier the clock stays put. Now allocates in this mode.
+func (tk *TimeKeeper) SetMonotonic(monotonic bool) {
 	tk.mutex.Lock()
 	defer tk.mutex.Unlock()
+	tk.monotonic = monotonic
+	tk.lastNow.Store(nil)
+	tk.publish()
+}
 
-	elapsedReal := time.Since(tk.startRealTime)
-	elapsedSim := time.Duration(float64(elapsedReal) * tk.multiplier)
+// now computes the current simulated time, the caller must hold the mutex
+func (tk *TimeKeeper) now() time.Time {
+	return tk.simAt(tk.realNow())
+}
+
+// simAt maps a real instant onto the simulated timeline
+func (v *view) simAt(realTime time.Time) time.Time {
+	if v.paused {
+		return v.pausedAt
+	}
+	if v.manual {
+		return v.startSimTime
+	}
 
-	return tk.startSimTime.Add(elapsedSim)
+	elapsedReal := realTime.Sub(v.startRealTime)
+	elapsedSim := time.Duration(float64(elapsedReal) * v.multiplier)
+	if v.slewRate != 0 {
+		slewed := min(elapsedReal, v.slewUntil.Sub(v.startRealTime))
+		if slewed > 0 {
+			elapsedSim += time.Duration(float64(slewed) * v.slewRate)
+		}
+	}
+
+	if v.leapSeconds {
+		return leapAdd(v.startSimTime, elapsedSim).In(v.location)
+	}
+	return v.startSimTime.Add(elapsedSim).In(v.location)
 }
 
-// Duration calculates the simulated duration between two timestamps
-func (tk *TimeKeeper) Duration(from, to time.Time) time.Duration {
-	return time.Duration(float64(to.Sub(from)) * tk.multiplier)
+// RealToSim maps a wall-clock instant onto the simulated timeline using the
+// current anchors and multiplier
+func (tk *TimeKeeper) RealToSim(t time.Time) time.Time {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.simAt(t)
 }
 
-// SetMultiplier updates the time speed dynamically
-func (tk *TimeKeeper) SetMultiplier(multiplier float64) {
+// SimToReal maps a simulated instant back to the wall-clock instant at
+// which the simulation reads it. The inverse is undefined while paused or
+// with a multiplier of zero, and the zero time is returned in that case.
+func (tk *TimeKeeper) SimToReal(t time.Time) time.Time {
 	tk.mutex.Lock()
 	defer tk.mutex.Unlock()
-	tk.multiplier = multiplier
+	return tk.realAt(t)
 }
 
-// Reset restarts the simulation with the initial settings
-func (tk *TimeKeeper) Reset() {
+// SkewFromReal returns how far the real clock is ahead of SimToReal(Now()),
+// both read under one lock. It stays close to zero while the anchors are
+// consistent and only grows by what the read pipeline adds, such as
+// quantization or bounds. A jump points at a bad rebase after a sync or a
+// speed change. It is zero while the inverse is undefined.
+func (tk *TimeKeeper) SkewFromReal() time.Duration {
 	tk.mutex.Lock()
 	defer tk.mutex.Unlock()
-	tk.startRealTime = time.Now()
+
+	realTime := tk.realNow()
+	mapped := tk.realAt(tk.current())
+	if mapped.IsZero() {
+		return 0
+	}
+	return realTime.Sub(mapped)
 }
 
-// Synchronize fetches time from a remote API
-func (tk *TimeKeeper) Synchronize(url string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
+// RealTimeFor returns the wall-clock instant at which the simulated clock
+// reaches simInstant at the current speed, like SimToReal. It returns an
+// error wrapping ErrUnreachable when the clock is paused, manual or frozen.
+// It does the same when simInstant is behind the clock in its direction of
+// travel: in the simulated past, or in the future with a negative
+// multiplier. The result is only valid until the speed or the time is
+// changed.
+func (tk *TimeKeeper) RealTimeFor(simInstant time.Time) (time.Time, error) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	switch now := tk.now(); {
+	case tk.paused:
+		return time.Time{}, fmt.Errorf("%w: the clock is paused", ErrUnreachable)
+	case tk.manual:
+		return time.Time{}, fmt.Errorf("%w: the clock only moves in manual steps", ErrUnreachable)
+	case tk.multiplier == 0:
+		return time.Time{}, fmt.Errorf("%w: the clock is frozen", ErrUnreachable)
+	case tk.multiplier > 0 && simInstant.Before(now):
+		return time.Time{}, fmt.Errorf("%w: %s is in the simulated past", ErrUnreachable, simInstant)
+	case tk.multiplier < 0 && simInstant.After(now):
+		return time.Time{}, fmt.Errorf("%w: %s is ahead of a clock running backward", ErrUnreachable, simInstant)
 	}
-	defer resp.Body.Close()
+	return tk.realAt(simInstant), nil
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
+// realAt is the inverse of simAt
+func (v *view) realAt(simTime time.Time) time.Time {
+	if v.paused || v.manual || v.multiplier == 0 {
+		return time.Time{}
 	}
 
-	var data struct {
-		SimulatedTime string `json:"simulated_time"`
+	elapsedSim := simTime.Sub(v.startSimTime)
+	if v.leapSeconds {
+		elapsedSim = leapSub(simTime, v.startSimTime)
 	}
+	startRealTime := v.startRealTime
+	if window := v.slewUntil.Sub(startRealTime); v.slewRate != 0 && window > 0 {
+		windowSim := time.Duration(float64(window) * (v.multiplier + v.slewRate))
+		fraction := float64(elapsedSim) / float64(windowSim)
+		switch {
+		case windowSim == 0 || fraction < 0:
+		case fraction <= 1:
+			return startRealTime.Add(time.Duration(fraction * float64(window)))
+		default:
+			elapsedSim -= windowSim
+			startRealTime = v.slewUntil
+		}
+	}
+	elapsedReal := time.Duration(float64(elapsedSim) / v.multiplier)
 
-	if err := json.Unmarshal(body, &data); err != nil {
-		return err
+	return startRealTime.Add(elapsedReal)
+}
+
+// RealToSimBatch converts many wall-clock instants like RealToSim. The
+// anchors are read once so the whole slice maps onto the same timeline.
+func (tk *TimeKeeper) RealToSimBatch(ts []time.Time) []time.Time {
+	tk.mutex.Lock()
+	v := tk.view
+	tk.mutex.Unlock()
+
+	out := make([]time.Time, len(ts))
+	for i, t := range ts {
+		out[i] = v.simAt(t)
 	}
+	return out
+}
 
-	simTime, err := time.Parse(time.RFC3339, data.SimulatedTime)
-	if err != nil {
-		return err
+// SimToRealBatch converts many simulated instants like SimToReal. The
+// anchors are read once so the whole slice maps onto the same timeline.
+func (tk *TimeKeeper) SimToRealBatch(ts []time.Time) []time.Time {
+	tk.mutex.Lock()
+	v := tk.view
+	tk.mutex.Unlock()
+
+	out := make([]time.Time, len(ts))
+	for i, t := range ts {
+		out[i] = v.realAt(t)
+	}
+	return out
+}
+
+// realNow reads the real clock
+func (v *view) realNow() time.Time {
+	if v.nowFunc == nil {
+		return time.Now()
+	}
+	return v.nowFunc()
+}
+
+// SetNowFunc replaces the real clock driving the simulation, for instance
+// with a fake clock in tests. The simulated clock continues from its
+// current value. Passing nil restores time.Now. Timers still wait on real
+// time.
+func (tk *TimeKeeper) SetNowFunc(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	tk.rebase()
+	tk.nowFunc = now
+	tk.startRealTime = now()
+	tk.changed()
+}
+
+// Location returns the time zone the simulation runs in
+func (tk *TimeKeeper) Location() *time.Location {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.location
+}
+
+// NowUnix returns the current simulated time as seconds since the epoch
+func (tk *TimeKeeper) NowUnix() int64 {
+	return tk.Now().Unix()
+}
+
+// NowUnixMilli returns the current simulated time as milliseconds since
+// the epoch
+func (tk *TimeKeeper) NowUnixMilli() int64 {
+	return tk.Now().UnixMilli()
+}
+
+// NowUnixNano returns the current simulated time as nanoseconds since the
+// epoch
+func (tk *TimeKeeper) NowUnixNano() int64 {
+	return tk.Now().UnixNano()
+}
+
+// NowIn returns the current simulated time in loc instead of the location
+// of the simulation. A nil loc means UTC.
+func (tk *TimeKeeper) NowIn(loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return tk.Now().In(loc)
+}
+
+// Truncate returns the current simulated time rounded down to a multiple of
+// d on the wall clock of the location of the simulation. Truncating to an
+// hour yields the top of the local hour even in zones with offsets that are
+// not whole hours. A d of zero or below returns Now unchanged.
+func (tk *TimeKeeper) Truncate(d time.Duration) time.Time {
+	return onWallClock(tk.Now(), func(t time.Time) time.Time { return t.Truncate(d) })
+}
+
+// Round is like Truncate but rounds to the nearest multiple of d, halfway
+// values round up
+func (tk *TimeKeeper) Round(d time.Duration) time.Time {
+	return onWallClock(tk.Now(), func(t time.Time) time.Time { return t.Round(d) })
+}
+
+// onWallClock applies f to the wall-clock reading of t and maps the result
+// back into the location of t. A reading skipped or repeated by a daylight
+// saving transition is resolved like time.Date does.
+func onWallClock(t time.Time, f func(time.Time) time.Time) time.Time {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	wall = f(wall)
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), t.Location())
+}
+
+// Weekday returns the current simulated day of the week in the location of
+// the simulation
+func (tk *TimeKeeper) Weekday() time.Weekday {
+	return tk.Now().Weekday()
+}
+
+// Hour returns the current simulated hour of the day in the location of the
+// simulation, within [0, 23]
+func (tk *TimeKeeper) Hour() int {
+	return tk.Now().Hour()
+}
+
+// YearDay returns the current simulated day of the year in the location of
+// the simulation, within [1, 365] or [1, 366] in leap years
+func (tk *TimeKeeper) YearDay() int {
+	return tk.Now().YearDay()
+}
+
+// StartSimTime returns the simulated anchor of the current timeline
+// segment. It moves whenever the speed or the time is changed.
+func (tk *TimeKeeper) StartSimTime() time.Time {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.startSimTime
+}
+
+// StartRealTime returns the real anchor matching StartSimTime
+func (tk *TimeKeeper) StartRealTime() time.Time {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.startRealTime
+}
+
+// Format returns the current simulated time formatted with layout in the
+// location of the simulation
+func (tk *TimeKeeper) Format(layout string) string {
+	return tk.Now().Format(layout)
+}
+
+// FormatRFC3339 returns the current simulated time in RFC 3339 format
+func (tk *TimeKeeper) FormatRFC3339() string {
+	return tk.Format(time.RFC3339)
+}
+
+// rebase moves the anchors to the current instant so that later changes to
+// the multiplier only apply from now on. The caller must hold the mutex.
+func (tk *TimeKeeper) rebase() {
+	tk.startSimTime = tk.now()
+	tk.startRealTime = tk.realNow()
+}
+
+// setTime anchors the simulation at the given simulated instant. The caller
+// must hold the mutex.
+func (tk *TimeKeeper) setTime(simTime time.Time) {
+	tk.startSimTime = simTime.In(tk.location)
+	tk.startRealTime = tk.realNow()
+	tk.slewRate = 0
+	if tk.paused {
+		tk.pausedAt = tk.startSimTime
+	}
+	tk.changed()
+}
+
+// slewTo corrects the simulated clock towards target gradually over the
+// given real window instead of jumping. The correction is slowed down when
+// needed so the clock never runs backward. The caller must hold the mutex.
+func (tk *TimeKeeper) slewTo(target time.Time, window time.Duration) {
+	tk.rebase()
+	offset := target.Sub(tk.startSimTime)
+	if window <= 0 || tk.multiplier <= 0 {
+		tk.setTime(target)
+		return
+	}
+
+	rate := float64(offset) / float64(window)
+	if limit := -tk.multiplier / 2; rate < limit {
+		rate = limit
+		window = time.Duration(float64(offset) / rate)
+	}
+	tk.slewRate = rate
+	tk.slewUntil = tk.startRealTime.Add(window)
+	tk.changed()
+}
+
+// clampSlew keeps a pending correction from running the clock backward
+// after the multiplier changed. What remains of it is spread over a longer
+// window, or applied at once when the clock is frozen or runs backward. The
+// caller must hold the mutex and have rebased the anchors.
+func (tk *TimeKeeper) clampSlew() {
+	from := tk.startRealTime
+	if tk.paused {
+		from = tk.pausedRealTime
 	}
+	limit := -tk.multiplier / 2
+	if tk.slewRate >= limit || !tk.slewUntil.After(from) {
+		return
+	}
+
+	remaining := time.Duration(float64(tk.slewUntil.Sub(from)) * tk.slewRate)
+	if tk.multiplier <= 0 {
+		tk.startSimTime = tk.startSimTime.Add(remaining)
+		if tk.paused {
+			tk.pausedAt = tk.startSimTime
+		}
+		tk.slewRate = 0
+		return
+	}
+	tk.slewRate = limit
+	tk.slewUntil = from.Add(time.Duration(float64(remaining) / limit))
+}
+
+// Add returns the simulated instant reached once simDuration of simulated
+// time has elapsed from t. This is t.Add(simDuration) unless SetLeapSeconds
+// is enabled, in which case inserted leap seconds consume part of the
+// duration. Combined with SimToReal it gives the real instant to schedule
+// work at: tk.SimToReal(tk.Add(tk.Now(), time.Hour)) is when the simulation
+// will be one hour further.
+func (tk *TimeKeeper) Add(t time.Time, simDuration time.Duration) time.Time {
+	if tk.loadView().leapSeconds {
+		return leapAdd(t, simDuration).In(t.Location())
+	}
+	return t.Add(simDuration)
+}
+
+// Sub is the inverse of Add. It returns the simulated time that elapses
+// between u and t, counting inserted leap seconds when SetLeapSeconds is
+// enabled.
+func (tk *TimeKeeper) Sub(t, u time.Time) time.Duration {
+	if tk.loadView().leapSeconds {
+		return leapSub(t, u)
+	}
+	return t.Sub(u)
+}
+
+// AddDate returns the simulated time plus the given calendar years, months
+// and days in the location of the simulation. Like time.Time.AddDate it
+// keeps the wall-clock time of day across daylight saving transitions and
+// normalizes overflowing days, so one month after January 31 is March 2 or
+// 3. Add counts fixed durations instead.
+func (tk *TimeKeeper) AddDate(years, months, days int) time.Time {
+	return tk.Now().AddDate(years, months, days)
+}
+
+// Since returns the simulated time elapsed since t
+func (tk *TimeKeeper) Since(t time.Time) time.Duration {
+	return tk.Now().Sub(t)
+}
+
+// Until returns the simulated duration until t
+func (tk *TimeKeeper) Until(t time.Time) time.Duration {
+	return t.Sub(tk.Now())
+}
 
+// Duration calculates the simulated duration between two timestamps. The
+// result is negative when the multiplier is. Like time.Time.Sub it measures
+// absolute time, so wall-clock differences across a daylight saving
+// transition are off by the shift. With SetLeapSeconds the leap seconds
+// inserted in the simulated span are not counted.
+func (tk *TimeKeeper) Duration(from, to time.Time) time.Duration {
+	return tk.loadView().duration(from, to)
+}
+
+// DurationBatch converts many pairs of timestamps like Duration. The
+// multiplier is read once, so every pair is scaled by the same speed even
+// if it changes meanwhile.
+func (tk *TimeKeeper) DurationBatch(pairs [][2]time.Time) []time.Duration {
+	v := tk.loadView()
+	out := make([]time.Duration, len(pairs))
+	for i, pair := range pairs {
+		out[i] = v.duration(pair[0], pair[1])
+	}
+	return out
+}
+
+// duration scales the real span from from to to by the multiplier of v
+func (v *view) duration(from, to time.Time) time.Duration {
+	elapsed := time.Duration(float64(to.Sub(from)) * v.multiplier)
+	if v.leapSeconds {
+		start := v.simAt(from)
+		return leapAdd(start, elapsed).Sub(start)
+	}
+	return elapsed
+}
+
+// Preview returns the simulated time Now will read after waiting realDelta
+// of real time at the current multiplier. It returns the frozen value while
+// paused and the current value in manual mode. Slewing in progress, bounds
+// and loops are taken into account.
+func (tk *TimeKeeper) Preview(realDelta time.Duration) time.Time {
 	tk.mutex.Lock()
 	defer tk.mutex.Unlock()
-	tk.startSimTime = simTime
-	tk.startRealTime = time.Now()
+	t, _ := tk.clamp(tk.wrap(tk.simAt(tk.realNow().Add(realDelta))))
+	return t
+}
+
+// DurationAt is like Duration but scales by the given multiplier instead of
+// the current one, leap seconds included. It does not change the
+// TimeKeeper, so it can answer what if questions while other goroutines
+// read the clock.
+func (tk *TimeKeeper) DurationAt(from, to time.Time, multiplier float64) time.Duration {
+	v := *tk.loadView()
+	v.multiplier = multiplier
+	return v.duration(from, to)
+}
+
+// Sleep blocks for the given simulated duration. The wake up follows any
+// speed change made during the sleep. With a multiplier of zero or below on
+// entry the simulated clock never advances by d, so it returns immediately.
+// In manual mode it blocks until Advance moves the clock past the deadline.
+func (tk *TimeKeeper) Sleep(d time.Duration) {
+	tk.mutex.Lock()
+	if d <= 0 || (!tk.manual && tk.multiplier <= 0) {
+		tk.mutex.Unlock()
+		return
+	}
+
+	done := make(chan struct{})
+	tk.startTimer(&SimTimer{
+		tk:   tk,
+		when: tk.now().Add(d),
+		f:    func(time.Time) { close(done) },
+	})
+	closing := tk.closing()
+	tk.mutex.Unlock()
+
+	select {
+	case <-done:
+	case <-closing:
+	}
+}
+
+// SetMultiplier updates the time speed dynamically. The simulated clock
+// continues from its current value at the new speed. Zero freezes the clock
+// and negative values run it backward. In manual mode it only scales later
+// calls to Step. Values outside the range set with SetMultiplierRange are
+// clamped into it. With a limit set by SetMaxAcceleration the change is
+// eased in like RampMultiplier. It is a no-op for values rejected by
+// SetMultiplierChecked for any other reason.
+func (tk *TimeKeeper) SetMultiplier(multiplier float64) {
+	tk.updateMultiplier(multiplier, true)
+}
+
+// SetMultiplierChecked is like SetMultiplier but returns an error instead
+// of ignoring or clamping the value. The error wraps ErrInvalidMultiplier
+// for NaN, infinities and, when the TimeKeeper was created
+// WithPositiveMultiplier, values of zero or below. It wraps
+// ErrMultiplierOutOfRange for values outside the range set with
+// SetMultiplierRange.
+func (tk *TimeKeeper) SetMultiplierChecked(multiplier float64) error {
+	return tk.updateMultiplier(multiplier, false)
+}
+
+// updateMultiplier validates and applies a new multiplier, clamping it into
+// the configured range or rejecting it
+func (tk *TimeKeeper) updateMultiplier(multiplier float64, clamp bool) error {
+	tk.mutex.Lock()
+	if tk.closed {
+		tk.mutex.Unlock()
+		return ErrClosed
+	}
+	if err := validateMultiplier(multiplier, tk.requirePositive); err != nil {
+		tk.mutex.Unlock()
+		return err
+	}
+	if tk.hasMultiplierRange {
+		if multiplier < tk.minMultiplier || multiplier > tk.maxMultiplier {
+			if !clamp {
+				tk.mutex.Unlock()
+				return fmt.Errorf("%w: %v is outside [%v, %v]", ErrMultiplierOutOfRange,
+					multiplier, tk.minMultiplier, tk.maxMultiplier)
+			}
+			multiplier = tk.clampMultiplier(multiplier)
+		}
+	}
+	if over := tk.accelerationTime(multiplier); over > 0 && !tk.manual {
+		tk.startRamp(multiplier, over)
+		tk.mutex.Unlock()
+		return nil
+	}
+	ev := tk.change(MultiplierChanged)
+	tk.rampGen++
+	tk.setMultiplier(multiplier)
+	observers := tk.commit(&ev)
+	metrics := tk.metrics
+	tk.mutex.Unlock()
 
+	metrics.observeMultiplier(multiplier)
+	notify(observers, ev)
 	return nil
 }
+
+// setMultiplier changes the speed from the current instant on. The caller
+// must hold the mutex.
+func (tk *TimeKeeper) setMultiplier(multiplier float64) {
+	tk.rebase()
+	tk.multiplier = multiplier
+	tk.clampSlew()
+	tk.changed()
+}
+
+// ElapsedReal returns the real time elapsed since the simulation started or
+// was last Reset
+func (tk *TimeKeeper) ElapsedReal() time.Duration {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.realNow().Sub(tk.initialRealTime)
+}
+
+// ElapsedSim returns the simulated time elapsed since the start time
+func (tk *TimeKeeper) ElapsedSim() time.Duration {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.current().Sub(tk.initialSimTime)
+}
+
+// TotalPaused returns the real time spent paused since the simulation
+// started or was last Reset, including the current pause
+func (tk *TimeKeeper) TotalPaused() time.Duration {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	total := tk.totalPaused
+	if tk.paused {
+		total += tk.realNow().Sub(tk.pausedRealTime)
+	}
+	return total
+}
+
+// ActiveSimElapsed returns the simulated time elapsed since the start time
+// while the simulation was running. Resume continues from the paused value,
+// so pauses never count and it matches ElapsedSim. It is the simulated
+// counterpart of ElapsedReal minus TotalPaused.
+func (tk *TimeKeeper) ActiveSimElapsed() time.Duration {
+	return tk.ElapsedSim()
+}
+
+// Multiplier returns the current time speed
+func (tk *TimeKeeper) Multiplier() float64 {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.multiplier
+}
+
+// EffectiveRate returns how many simulated seconds currently elapse per
+// real second. It equals Multiplier when no transient is active. It follows
+// the current step of a RampMultiplier and includes the correction of a
+// slewed sync. It is zero while paused or in manual mode.
+func (tk *TimeKeeper) EffectiveRate() float64 {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	if tk.paused || tk.manual {
+		return 0
+	}
+	rate := tk.multiplier
+	if tk.slewRate != 0 && tk.realNow().Before(tk.slewUntil) {
+		rate += tk.slewRate
+	}
+	return rate
+}
+
+// Reset restarts the simulation with the initial settings, restoring the
+// start time and multiplier passed to New
+func (tk *TimeKeeper) Reset() {
+	tk.mutex.Lock()
+	ev := tk.change(Restarted)
+	tk.rampGen++
+	tk.startSimTime = tk.initialSimTime
+	tk.multiplier = tk.initialMultiplier
+	tk.startRealTime = tk.realNow()
+	tk.initialRealTime = tk.startRealTime
+	tk.totalPaused = 0
+	tk.slewRate = 0
+	tk.paused = false
+	tk.changed()
+	observers := tk.commit(&ev)
+	metrics := tk.metrics
+	tk.mutex.Unlock()
+
+	metrics.observeMultiplier(ev.NewMultiplier)
+	notify(observers, ev)
+}
+
+// SetTime jumps the simulation to the given simulated instant, unlike
+// Reset the current multiplier is kept
+func (tk *TimeKeeper) SetTime(t time.Time) {
+	tk.mutex.Lock()
+	ev := tk.change(TimeSet)
+	tk.setTime(t)
+	observers := tk.commit(&ev)
+	tk.mutex.Unlock()
+
+	notify(observers, ev)
+}
+
+// Pause freezes the simulated clock at its current value
+func (tk *TimeKeeper) Pause() {
+	tk.mutex.Lock()
+	if tk.paused {
+		tk.mutex.Unlock()
+		return
+	}
+	ev := tk.change(Paused)
+	tk.pause()
+	observers := tk.commit(&ev)
+	tk.mutex.Unlock()
+
+	notify(observers, ev)
+}
+
+// pause freezes the clock, the caller must hold the mutex
+func (tk *TimeKeeper) pause() {
+	tk.pausedRealTime = tk.realNow()
+	tk.pausedAt = tk.simAt(tk.pausedRealTime)
+	tk.paused = true
+	tk.changed()
+}
+
+// Resume continues the simulation from the value it was paused at. The real
+// time spent paused is not counted. A resume scheduled with ResumeAfter is
+// cancelled.
+func (tk *TimeKeeper) Resume() {
+	tk.mutex.Lock()
+	tk.cancelResume()
+	if !tk.paused {
+		tk.mutex.Unlock()
+		return
+	}
+	ev := tk.change(Resumed)
+	tk.startSimTime = tk.pausedAt
+	tk.startRealTime = tk.realNow()
+	tk.totalPaused += tk.startRealTime.Sub(tk.pausedRealTime)
+	if tk.slewRate != 0 {
+		tk.slewUntil = tk.slewUntil.Add(tk.startRealTime.Sub(tk.pausedRealTime))
+	}
+	tk.paused = false
+	tk.changed()
+	observers := tk.commit(&ev)
+	tk.mutex.Unlock()
+
+	notify(observers, ev)
+}
+
+// String describes the state of the TimeKeeper for logs
+func (tk *TimeKeeper) String() string {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	state := ""
+	if tk.paused {
+		state = ", paused"
+	}
+	return fmt.Sprintf("TimeKeeper(now=%s, mult=%g, tz=%s%s)",
+		tk.current().Format(time.RFC3339), tk.multiplier, tk.location, state)
+}
+
+// IsPaused reports whether the simulated clock is paused
+func (tk *TimeKeeper) IsPaused() bool {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.paused
+}
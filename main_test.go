@@ -0,0 +1,1007 @@
+package timewarp
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMultiplierValidation(t *testing.T) {
+	tests := []struct {
+		multiplier float64
+		valid      bool
+	}{
+		{math.NaN(), false},
+		{math.Inf(1), false},
+		{math.Inf(-1), false},
+		{2.5, true},
+	}
+	for _, tt := range tests {
+		_, err := New("2024-01-01", "00:00", "UTC", tt.multiplier)
+		if tt.valid != (err == nil) {
+			t.Errorf("New with multiplier %v: err = %v", tt.multiplier, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidMultiplier) {
+			t.Errorf("New with multiplier %v: err = %v, want ErrInvalidMultiplier", tt.multiplier, err)
+		}
+
+		tk, err := New("2024-01-01", "00:00", "UTC", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tk.SetMultiplierChecked(tt.multiplier)
+		if tt.valid != (err == nil) {
+			t.Errorf("SetMultiplierChecked(%v) = %v", tt.multiplier, err)
+		}
+		want := 1.0
+		if tt.valid {
+			want = tt.multiplier
+		}
+		tk.SetMultiplier(tt.multiplier)
+		if got := tk.Multiplier(); got != want {
+			t.Errorf("Multiplier() after SetMultiplier(%v) = %v, want %v", tt.multiplier, got, want)
+		}
+	}
+}
+
+func TestDurationAt(t *testing.T) {
+	tk, err := NewWithOptions(WithStartTime(time.Date(2016, 12, 31, 23, 59, 0, 0, time.UTC)), WithMultiplier(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Now()
+	to := from.Add(time.Minute)
+
+	if got, want := tk.DurationAt(from, to, 2), tk.Duration(from, to); got != want {
+		t.Errorf("DurationAt at the current multiplier = %v, Duration = %v", got, want)
+	}
+	if got := tk.DurationAt(from, to, 4); got != 4*time.Minute {
+		t.Errorf("DurationAt(x4) = %v, want %v", got, 4*time.Minute)
+	}
+	if m := tk.Multiplier(); m != 2 {
+		t.Errorf("DurationAt changed the multiplier to %v", m)
+	}
+
+	// the leap second at the end of 2016 falls within the next two minutes
+	tk.SetLeapSeconds(true)
+	if got, want := tk.DurationAt(from, to, 2), tk.Duration(from, to); got != want {
+		t.Errorf("DurationAt with leap seconds = %v, Duration = %v", got, want)
+	}
+	if got := tk.Duration(from, to); got != 2*time.Minute-time.Second {
+		t.Errorf("Duration across a leap second = %v, want %v", got, 2*time.Minute-time.Second)
+	}
+}
+
+// benchmarkTimes returns n real instants spread over a minute
+func benchmarkTimes(n int) []time.Time {
+	base := time.Now()
+	ts := make([]time.Time, n)
+	for i := range ts {
+		ts[i] = base.Add(time.Duration(i) * time.Minute / time.Duration(n))
+	}
+	return ts
+}
+
+func BenchmarkRealToSim(b *testing.B) {
+	tk, _ := New("2024-01-01", "00:00", "UTC", 60)
+	ts := benchmarkTimes(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, t := range ts {
+			tk.RealToSim(t)
+		}
+	}
+}
+
+func BenchmarkRealToSimBatch(b *testing.B) {
+	tk, _ := New("2024-01-01", "00:00", "UTC", 60)
+	ts := benchmarkTimes(1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tk.RealToSimBatch(ts)
+	}
+}
+
+func BenchmarkSimToReal(b *testing.B) {
+	tk, _ := New("2024-01-01", "00:00", "UTC", 60)
+	ts := tk.RealToSimBatch(benchmarkTimes(1000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, t := range ts {
+			tk.SimToReal(t)
+		}
+	}
+}
+
+func BenchmarkSimToRealBatch(b *testing.B) {
+	tk, _ := New("2024-01-01", "00:00", "UTC", 60)
+	ts := tk.RealToSimBatch(benchmarkTimes(1000))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tk.SimToRealBatch(ts)
+	}
+}
+
+func TestBatchMatchesSingle(t *testing.T) {
+	tk, err := NewWithOptions(WithStartTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), WithMultiplier(60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := benchmarkTimes(10)
+	sims := tk.RealToSimBatch(ts)
+	reals := tk.SimToRealBatch(sims)
+	for i := range ts {
+		if want := tk.RealToSim(ts[i]); !sims[i].Equal(want) {
+			t.Errorf("RealToSimBatch[%d] = %v, RealToSim = %v", i, sims[i], want)
+		}
+		if d := reals[i].Sub(ts[i]); d < -time.Microsecond || d > time.Microsecond {
+			t.Errorf("SimToRealBatch[%d] = %v, want %v", i, reals[i], ts[i])
+		}
+	}
+}
+
+func TestNowDoesNotAllocate(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := testing.AllocsPerRun(100, func() { tk.Now() }); n != 0 {
+		t.Errorf("Now() allocates %v times per call", n)
+	}
+}
+
+func TestNowConcurrentWithWriters(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					tk.Now()
+				}
+			}
+		}()
+	}
+	for i := 0; i < 1000; i++ {
+		switch i % 4 {
+		case 0:
+			tk.SetMultiplier(float64(i%7 + 1))
+		case 1:
+			tk.Reset()
+		case 2:
+			tk.SetTime(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+		case 3:
+			tk.Pause()
+			tk.Resume()
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// lockedNow reads the clock under the mutex like Now did before the
+// published views
+func (tk *TimeKeeper) lockedNow() time.Time {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.current()
+}
+
+func BenchmarkNow(b *testing.B) {
+	tk, _ := New("2024-01-01", "00:00", "UTC", 60)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			tk.Now()
+		}
+	})
+}
+
+func BenchmarkNowLocked(b *testing.B) {
+	tk, _ := New("2024-01-01", "00:00", "UTC", 60)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			tk.lockedNow()
+		}
+	})
+}
+
+func BenchmarkNowMonotonic(b *testing.B) {
+	tk, _ := New("2024-01-01", "00:00", "UTC", 60)
+	tk.SetMonotonic(true)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			tk.Now()
+		}
+	})
+}
+
+func TestDurationConcurrentWithSetMultiplier(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Now()
+	to := from.Add(time.Second)
+	pairs := [][2]time.Time{{from, to}, {to, from}}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			tk.SetMultiplier(float64(i%4 + 1))
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if d := tk.Duration(from, to); d < time.Second || d > 4*time.Second {
+			t.Fatalf("Duration of a real second = %v, want 1s to 4s", d)
+		}
+		if ds := tk.DurationBatch(pairs); ds[0] != -ds[1] {
+			t.Fatalf("DurationBatch mixed multipliers: %v", ds)
+		}
+	}
+}
+
+func BenchmarkDuration(b *testing.B) {
+	tk, _ := New("2024-01-01", "00:00", "UTC", 60)
+	ts := benchmarkTimes(1001)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j+1 < len(ts); j++ {
+			tk.Duration(ts[j], ts[j+1])
+		}
+	}
+}
+
+func BenchmarkDurationBatch(b *testing.B) {
+	tk, _ := New("2024-01-01", "00:00", "UTC", 60)
+	ts := benchmarkTimes(1001)
+	pairs := make([][2]time.Time, len(ts)-1)
+	for j := range pairs {
+		pairs[j] = [2]time.Time{ts[j], ts[j+1]}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tk.DurationBatch(pairs)
+	}
+}
+
+func TestSetMultiplierIsContinuous(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	for _, multiplier := range []float64{1000, 0.001, 60} {
+		before := tk.Now()
+		tk.SetMultiplier(multiplier)
+		after := tk.Now()
+		if d := after.Sub(before); d < 0 || d > 5*time.Millisecond*time.Duration(max(multiplier, 1)) {
+			t.Errorf("SetMultiplier(%v) moved the clock by %v", multiplier, d)
+		}
+	}
+}
+
+func TestResetRestoresStart(t *testing.T) {
+	tk, err := New("2024-01-01", "12:00", "UTC", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tk.Now()
+	time.Sleep(20 * time.Millisecond)
+	tk.SetMultiplier(5)
+	tk.Reset()
+	if d := tk.Now().Sub(start); d < 0 || d > 5*time.Second {
+		t.Errorf("Now() after Reset = %v, want about %v", tk.Now(), start)
+	}
+	if m := tk.Multiplier(); m != 1000 {
+		t.Errorf("Multiplier() after Reset = %v, want 1000", m)
+	}
+}
+
+func TestNowInLocation(t *testing.T) {
+	tk, err := New("2024-01-01", "09:00", "Asia/Tokyo", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tk.Location().String(); got != "Asia/Tokyo" {
+		t.Errorf("Location() = %q, want Asia/Tokyo", got)
+	}
+	now := tk.Now()
+	if now.Location() != tk.Location() {
+		t.Errorf("Now() is in %v, want %v", now.Location(), tk.Location())
+	}
+	if now.Hour() != 9 {
+		t.Errorf("Now() = %v, want 09:00 in Asia/Tokyo", now)
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tk.Pause()
+	if !tk.IsPaused() {
+		t.Fatal("IsPaused() = false after Pause")
+	}
+	paused := tk.Now()
+	time.Sleep(50 * time.Millisecond)
+	if now := tk.Now(); !now.Equal(paused) {
+		t.Errorf("Now() moved from %v to %v while paused", paused, now)
+	}
+
+	tk.Resume()
+	if tk.IsPaused() {
+		t.Fatal("IsPaused() = true after Resume")
+	}
+	if d := tk.Now().Sub(paused); d < 0 || d > 5*time.Second {
+		t.Errorf("Now() jumped by %v on Resume", d)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if !tk.Now().After(paused) {
+		t.Errorf("Now() = %v did not advance after Resume", tk.Now())
+	}
+}
+
+func TestMultiplierGetter(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.SetMultiplier(3.5)
+	if got := tk.Multiplier(); got != 3.5 {
+		t.Errorf("Multiplier() = %v, want 3.5", got)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	simStart := tk.Now()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		tk.SetMultiplier(6000)
+	}()
+	tk.Sleep(time.Minute)
+	if elapsed := tk.Now().Sub(simStart); elapsed < time.Minute {
+		t.Errorf("Sleep(1m) returned after %v of simulated time", elapsed)
+	}
+	if real := time.Since(start); real > 80*time.Millisecond {
+		t.Errorf("Sleep(1m) did not follow the speed change, took %v", real)
+	}
+
+	tk.SetMultiplier(0)
+	done := make(chan struct{})
+	go func() {
+		tk.Sleep(time.Hour)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Sleep with a zero multiplier did not return")
+	}
+}
+
+func TestRealSimRoundTrip(t *testing.T) {
+	for _, multiplier := range []float64{1, 60, 0.25, -3} {
+		tk, err := New("2024-01-01", "00:00", "UTC", multiplier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, offset := range []time.Duration{-time.Hour, 0, time.Second, 48 * time.Hour} {
+			real := time.Now().Add(offset)
+			if d := tk.SimToReal(tk.RealToSim(real)).Sub(real); d < -time.Microsecond || d > time.Microsecond {
+				t.Errorf("multiplier %v: SimToReal(RealToSim(t)) is %v off", multiplier, d)
+			}
+		}
+	}
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tk.SimToReal(tk.Now()); !got.IsZero() {
+		t.Errorf("SimToReal with a zero multiplier = %v, want the zero time", got)
+	}
+}
+
+func TestSinceUntil(t *testing.T) {
+	tk, err := New("2024-01-01", "12:00", "UTC", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.Pause()
+	now := tk.Now()
+
+	past := now.Add(-90 * time.Minute)
+	if got, want := tk.Since(past), now.Sub(past); got != want {
+		t.Errorf("Since = %v, want %v", got, want)
+	}
+	future := now.Add(3 * time.Hour)
+	if got, want := tk.Until(future), future.Sub(now); got != want {
+		t.Errorf("Until = %v, want %v", got, want)
+	}
+}
+
+func TestSetTime(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := time.Date(2031, 7, 4, 18, 30, 0, 0, time.UTC)
+	tk.SetTime(target)
+	if d := tk.Now().Sub(target); d < 0 || d > 10*time.Millisecond {
+		t.Errorf("Now() after SetTime = %v, want %v", tk.Now(), target)
+	}
+	if m := tk.Multiplier(); m != 2 {
+		t.Errorf("SetTime changed the multiplier to %v", m)
+	}
+}
+
+func TestNegativeAndZeroMultiplier(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(start),
+		WithMultiplier(-2),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	real = real.Add(time.Minute)
+	if got, want := tk.Now(), start.Add(-2*time.Minute); !got.Equal(want) {
+		t.Errorf("Now() at -2x = %v, want %v", got, want)
+	}
+	if got := tk.Duration(real, real.Add(time.Minute)); got != -2*time.Minute {
+		t.Errorf("Duration at -2x = %v, want -2m", got)
+	}
+	if got := tk.SimToReal(start.Add(-4 * time.Minute)); !got.Equal(real.Add(time.Minute)) {
+		t.Errorf("SimToReal at -2x = %v, want %v", got, real.Add(time.Minute))
+	}
+
+	tk.SetMultiplier(0)
+	frozen := tk.Now()
+	real = real.Add(time.Hour)
+	if got := tk.Now(); !got.Equal(frozen) {
+		t.Errorf("Now() at 0x moved from %v to %v", frozen, got)
+	}
+	if got := tk.Duration(real, real.Add(time.Minute)); got != 0 {
+		t.Errorf("Duration at 0x = %v, want 0", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tk, err := New("2024-03-01", "08:30", "America/Los_Angeles", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.Pause()
+	if got, want := tk.Format("2006-01-02 15:04 MST"), "2024-03-01 08:30 PST"; got != want {
+		t.Errorf("Format = %q, want %q", got, want)
+	}
+	if got, want := tk.FormatRFC3339(), "2024-03-01T08:30:00-08:00"; got != want {
+		t.Errorf("FormatRFC3339 = %q, want %q", got, want)
+	}
+}
+
+func TestNewStartLayouts(t *testing.T) {
+	tests := []struct {
+		date, time string
+		want       time.Time
+	}{
+		{"2024-05-06", "07:08", time.Date(2024, 5, 6, 7, 8, 0, 0, time.UTC)},
+		{"2024-05-06", "07:08:09", time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)},
+		{"2024-05-06", "07:08:09.25", time.Date(2024, 5, 6, 7, 8, 9, 250000000, time.UTC)},
+		{"2024-05-06", "", time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)},
+		{"2024-05-06T07:08:09+02:00", "", time.Date(2024, 5, 6, 5, 8, 9, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		tk, err := New(tt.date, tt.time, "UTC", 1)
+		if err != nil {
+			t.Errorf("New(%q, %q): %v", tt.date, tt.time, err)
+			continue
+		}
+		if got := tk.StartSimTime(); !got.Equal(tt.want) {
+			t.Errorf("New(%q, %q) starts at %v, want %v", tt.date, tt.time, got, tt.want)
+		}
+	}
+
+	if _, err := New("06/05/2024", "07:08", "UTC", 1); !errors.Is(err, ErrInvalidTimeFormat) {
+		t.Errorf("New with a US date: err = %v, want ErrInvalidTimeFormat", err)
+	}
+}
+
+func TestElapsed(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(start),
+		WithMultiplier(10),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	real = real.Add(time.Minute)
+	tk.SetMultiplier(2)
+	real = real.Add(time.Minute)
+	if got := tk.ElapsedReal(); got != 2*time.Minute {
+		t.Errorf("ElapsedReal() = %v, want 2m", got)
+	}
+	if got, want := tk.ElapsedSim(), tk.Now().Sub(start); got != want || got != 12*time.Minute {
+		t.Errorf("ElapsedSim() = %v, want %v and 12m", got, want)
+	}
+}
+
+func TestStartAccessors(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(start),
+		WithMultiplier(10),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tk.StartSimTime().Equal(start) || !tk.StartRealTime().Equal(real) {
+		t.Errorf("anchors = %v, %v, want %v, %v", tk.StartSimTime(), tk.StartRealTime(), start, real)
+	}
+
+	real = real.Add(time.Minute)
+	tk.SetMultiplier(2)
+	if got, want := tk.StartSimTime(), start.Add(10*time.Minute); !got.Equal(want) {
+		t.Errorf("StartSimTime() after SetMultiplier = %v, want %v", got, want)
+	}
+	if got := tk.StartRealTime(); !got.Equal(real) {
+		t.Errorf("StartRealTime() after SetMultiplier = %v, want %v", got, real)
+	}
+}
+
+func TestSubSecondStart(t *testing.T) {
+	tk, err := New("2024-01-01", "10:20:30.123456789", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.Pause()
+	if got := tk.Now().Nanosecond(); got < 123456789 || got > 123456789+int(10*time.Millisecond) {
+		t.Errorf("Now() = %v lost the fraction of the start", tk.Now())
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 500, time.UTC)
+	tk, err = NewWithOptions(WithStartTime(start), WithMultiplier(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tk.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+}
+
+func TestFormatAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk := NewManual(time.Date(2024, 3, 10, 1, 30, 0, 0, newYork))
+	if got, want := tk.Format("15:04 MST"), "01:30 EST"; got != want {
+		t.Errorf("Format before the transition = %q, want %q", got, want)
+	}
+	// one hour of absolute time skips the missing wall-clock hour
+	tk.Advance(time.Hour)
+	if got, want := tk.Format("15:04 MST"), "03:30 EDT"; got != want {
+		t.Errorf("Format after the transition = %q, want %q", got, want)
+	}
+
+	tk = NewManual(time.Date(2024, 11, 3, 0, 30, 0, 0, newYork))
+	tk.Advance(2 * time.Hour)
+	if got, want := tk.Format("15:04 MST"), "01:30 EST"; got != want {
+		t.Errorf("Format after the fall transition = %q, want %q", got, want)
+	}
+}
+
+func TestNewFromNow(t *testing.T) {
+	tk, err := NewFromNow(1, "Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := tk.Now().Sub(time.Now()); d < -5*time.Millisecond || d > 5*time.Millisecond {
+		t.Errorf("Now() is %v away from the real clock", d)
+	}
+	if loc := tk.Now().Location().String(); loc != "Europe/Berlin" {
+		t.Errorf("Now() is in %q, want Europe/Berlin", loc)
+	}
+}
+
+func TestString(t *testing.T) {
+	tk, err := NewWithOptions(WithStartTime(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)), WithMultiplier(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.Pause()
+	if got, want := tk.String(), "TimeKeeper(now=2025-01-02T03:04:05Z, mult=10, tz=UTC, paused)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestNowFunc(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(start),
+		WithMultiplier(5),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tk.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+	real = real.Add(3 * time.Second)
+	if got, want := tk.Now(), start.Add(15*time.Second); !got.Equal(want) {
+		t.Errorf("Now() after 3s at 5x = %v, want %v", got, want)
+	}
+
+	other := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk.SetNowFunc(func() time.Time { return other })
+	other = other.Add(time.Second)
+	if got, want := tk.Now(), start.Add(20*time.Second); !got.Equal(want) {
+		t.Errorf("Now() after switching clocks = %v, want %v", got, want)
+	}
+}
+
+func TestNowUnix(t *testing.T) {
+	tk, err := NewWithOptions(WithStartTime(time.Date(2024, 1, 1, 0, 0, 0, 123456789, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.Pause()
+	now := tk.Now()
+	if got := tk.NowUnix(); got != now.Unix() {
+		t.Errorf("NowUnix() = %d, want %d", got, now.Unix())
+	}
+	if got := tk.NowUnixMilli(); got != now.UnixMilli() {
+		t.Errorf("NowUnixMilli() = %d, want %d", got, now.UnixMilli())
+	}
+	if got := tk.NowUnixNano(); got != now.UnixNano() {
+		t.Errorf("NowUnixNano() = %d, want %d", got, now.UnixNano())
+	}
+}
+
+func TestEffectiveRate(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	if got := tk.EffectiveRate(); got != 2 {
+		t.Errorf("EffectiveRate() without a transient = %v, want 2", got)
+	}
+
+	tk.RampMultiplier(102, 200*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if got := tk.EffectiveRate(); got <= 2 || got >= 102 {
+		t.Errorf("EffectiveRate() during the ramp = %v, want the current step between 2 and 102", got)
+	}
+
+	tk.Pause()
+	if got := tk.EffectiveRate(); got != 0 {
+		t.Errorf("EffectiveRate() while paused = %v, want 0", got)
+	}
+}
+
+func TestNewTimeZone(t *testing.T) {
+	tests := []struct {
+		zone  string
+		valid bool
+	}{
+		{"", false},
+		{"UTC", true},
+		{"Local", true},
+		{"Mars/Olympus_Mons", false},
+	}
+	for _, tt := range tests {
+		tk, err := New("2024-01-01", "00:00", tt.zone, 1)
+		if tt.valid != (err == nil) {
+			t.Errorf("New with zone %q: err = %v", tt.zone, err)
+			continue
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidTimezone) {
+			t.Errorf("New with zone %q: err = %v, want ErrInvalidTimezone", tt.zone, err)
+		}
+		if tt.valid && tk.Location().String() != tt.zone {
+			t.Errorf("New with zone %q runs in %v", tt.zone, tk.Location())
+		}
+	}
+}
+
+func TestCalendarGetters(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk := NewManual(time.Date(2024, 3, 30, 23, 30, 0, 0, berlin))
+	if tk.Weekday() != time.Saturday || tk.Hour() != 23 || tk.YearDay() != 90 {
+		t.Errorf("before the transition got %v, hour %d, day %d", tk.Weekday(), tk.Hour(), tk.YearDay())
+	}
+	// clocks go forward at 02:00 on the last Sunday of March
+	tk.Advance(3 * time.Hour)
+	if tk.Weekday() != time.Sunday || tk.Hour() != 3 || tk.YearDay() != 91 {
+		t.Errorf("after the transition got %v, hour %d, day %d, want Sunday, hour 3, day 91",
+			tk.Weekday(), tk.Hour(), tk.YearDay())
+	}
+}
+
+func TestNowIn(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk := NewManual(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	now := tk.NowIn(tokyo)
+	if now.Location() != tokyo || now.Hour() != 21 {
+		t.Errorf("NowIn(Asia/Tokyo) = %v, want 21:00 JST", now)
+	}
+	if _, offset := now.Zone(); offset != 9*60*60 {
+		t.Errorf("NowIn(Asia/Tokyo) offset = %d, want %d", offset, 9*60*60)
+	}
+	if !now.Equal(tk.Now()) {
+		t.Errorf("NowIn(Asia/Tokyo) = %v is a different instant than %v", now, tk.Now())
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	tk := NewManual(time.Date(2016, 12, 31, 23, 0, 0, 0, time.UTC))
+	now := tk.Now()
+	if got, want := tk.Add(now, 2*time.Hour), now.Add(2*time.Hour); !got.Equal(want) {
+		t.Errorf("Add = %v, want %v", got, want)
+	}
+
+	tk.SetLeapSeconds(true)
+	later := tk.Add(now, 2*time.Hour)
+	if want := time.Date(2017, 1, 1, 0, 59, 59, 0, time.UTC); !later.Equal(want) {
+		t.Errorf("Add across the leap second = %v, want %v", later, want)
+	}
+	if got := tk.Sub(later, now); got != 2*time.Hour {
+		t.Errorf("Sub(Add(t, 2h), t) = %v, want 2h", got)
+	}
+}
+
+func TestTruncateRound(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk := NewManual(time.Date(2024, 1, 1, 10, 37, 0, 0, kolkata))
+
+	got := tk.Truncate(time.Hour)
+	if want := time.Date(2024, 1, 1, 10, 0, 0, 0, kolkata); !got.Equal(want) || got.Location() != kolkata {
+		t.Errorf("Truncate(time.Hour) = %v, want %v", got, want)
+	}
+	if got, want := tk.Round(time.Hour), time.Date(2024, 1, 1, 11, 0, 0, 0, kolkata); !got.Equal(want) {
+		t.Errorf("Round(time.Hour) = %v, want %v", got, want)
+	}
+	if got := tk.Truncate(0); !got.Equal(tk.Now()) {
+		t.Errorf("Truncate(0) = %v, want %v", got, tk.Now())
+	}
+}
+
+func TestPreview(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, multiplier := range []float64{1, 60, 0.5} {
+		tk.SetMultiplier(multiplier)
+		want := tk.Now().Add(time.Duration(30 * float64(time.Second) * multiplier))
+		if got := tk.Preview(30 * time.Second); !got.Equal(want) {
+			t.Errorf("Preview(30s) at %vx = %v, want %v", multiplier, got, want)
+		}
+		real = real.Add(30 * time.Second)
+		if got := tk.Now(); !got.Equal(want) {
+			t.Errorf("Now() 30s later at %vx = %v, want %v", multiplier, got, want)
+		}
+	}
+
+	tk.Pause()
+	if got, want := tk.Preview(time.Hour), tk.Now(); !got.Equal(want) {
+		t.Errorf("Preview(1h) while paused = %v, want %v", got, want)
+	}
+}
+
+func TestRealTimeFor(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC)),
+		WithMultiplier(60),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a simulated day at 60x takes 24 real minutes
+	got, err := tk.RealTimeFor(time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := real.Add(24 * time.Minute); !got.Equal(want) {
+		t.Errorf("RealTimeFor(2025-12-25) = %v, want %v", got, want)
+	}
+
+	if _, err := tk.RealTimeFor(time.Date(2025, 12, 23, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("RealTimeFor(past) error = %v, want ErrUnreachable", err)
+	}
+	tk.SetMultiplier(0)
+	if _, err := tk.RealTimeFor(time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrUnreachable) {
+		t.Errorf("RealTimeFor at 0x error = %v, want ErrUnreachable", err)
+	}
+}
+
+func TestSkewFromReal(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	check := func(when string) {
+		t.Helper()
+		if skew := tk.SkewFromReal(); skew < -time.Millisecond || skew > time.Millisecond {
+			t.Errorf("SkewFromReal() %s = %v, want about 0", when, skew)
+		}
+	}
+	check("after New")
+	tk.SetMultiplier(1000)
+	check("after SetMultiplier")
+	time.Sleep(20 * time.Millisecond)
+	check("20ms after SetMultiplier")
+}
+
+func TestTotalPaused(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+		WithMultiplier(60),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	real = real.Add(time.Second)
+	tk.Pause()
+	real = real.Add(3 * time.Second)
+	tk.Resume()
+	real = real.Add(time.Second)
+	tk.Pause()
+	real = real.Add(2 * time.Second)
+	if got := tk.TotalPaused(); got != 5*time.Second {
+		t.Errorf("TotalPaused() during the second pause = %v, want 5s", got)
+	}
+	tk.Resume()
+	real = real.Add(time.Second)
+
+	if got := tk.TotalPaused(); got != 5*time.Second {
+		t.Errorf("TotalPaused() = %v, want 5s", got)
+	}
+	// three running real seconds at 60x
+	if got := tk.ActiveSimElapsed(); got != 3*time.Minute {
+		t.Errorf("ActiveSimElapsed() = %v, want 3m", got)
+	}
+
+	tk.Reset()
+	if got := tk.TotalPaused(); got != 0 {
+		t.Errorf("TotalPaused() after Reset = %v, want 0", got)
+	}
+}
+
+func TestAddDate(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		start time.Time
+		want  time.Time
+	}{
+		{
+			// time.Time.AddDate normalizes April 31 to May 1
+			name:  "31 to 30 day month",
+			start: time.Date(2024, 3, 31, 12, 0, 0, 0, newYork),
+			want:  time.Date(2024, 5, 1, 12, 0, 0, 0, newYork),
+		},
+		{
+			name:  "daylight saving change",
+			start: time.Date(2024, 2, 15, 9, 0, 0, 0, newYork),
+			want:  time.Date(2024, 3, 15, 9, 0, 0, 0, newYork),
+		},
+	}
+	for _, tt := range tests {
+		tk := NewManual(tt.start)
+		got := tk.AddDate(0, 1, 0)
+		if !got.Equal(tt.want) || got.Location() != newYork {
+			t.Errorf("%s: AddDate(0, 1, 0) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// the month across the change is an hour short of 29 days
+	tk := NewManual(time.Date(2024, 2, 15, 9, 0, 0, 0, newYork))
+	if got := tk.AddDate(0, 1, 0).Sub(tk.Now()); got != 29*24*time.Hour-time.Hour {
+		t.Errorf("AddDate(0, 1, 0) is %v after Now, want 695h", got)
+	}
+}
+
+func TestNewFromUnix(t *testing.T) {
+	// 2024-01-01T00:00:00Z
+	const sec = 1704067200
+	want := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tk, err := NewFromUnix(sec, "America/New_York", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	now := tk.Now()
+	if d := now.Sub(want); d < 0 || d > 100*time.Millisecond {
+		t.Errorf("Now() = %v, want about %v", now, want)
+	}
+	if now.Location().String() != "America/New_York" || now.Hour() != 19 {
+		t.Errorf("Now() = %v, want 19:00 in America/New_York", now)
+	}
+
+	milli, err := NewFromUnixMilli(sec*1000+250, "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer milli.Close()
+	if d := milli.Now().Sub(want.Add(250 * time.Millisecond)); d < 0 || d > 100*time.Millisecond {
+		t.Errorf("NewFromUnixMilli Now() = %v, want about %v", milli.Now(), want.Add(250*time.Millisecond))
+	}
+
+	if _, err := NewFromUnix(sec, "Nowhere/City", 1); err == nil {
+		t.Error("NewFromUnix with an unknown zone succeeded")
+	}
+}
@@ -0,0 +1,100 @@
+package timewarp
+
+import "time"
+
+// NewManual initializes a TimeKeeper in manual mode. Its clock starts at
+// start in the location of start and only moves when Advance is called.
+func NewManual(start time.Time) *TimeKeeper {
+	tk, _ := NewWithOptions(
+		WithStartTime(start),
+		WithLocation(start.Location()),
+	)
+	tk.manual = true
+	tk.publish()
+	return tk
+}
+
+// IsManual reports whether the TimeKeeper is in manual mode
+func (tk *TimeKeeper) IsManual() bool {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.manual
+}
+
+// Advance moves the simulated clock forward by d and runs the timers that
+// became due in order of their deadlines. Outside manual mode it jumps the
+// clock forward by d.
+func (tk *TimeKeeper) Advance(d time.Duration) {
+	tk.mutex.Lock()
+	if tk.leapSeconds {
+		tk.setTime(leapAdd(tk.now(), d))
+	} else {
+		tk.setTime(tk.now().Add(d))
+	}
+	tk.mutex.Unlock()
+
+	tk.fireDue()
+}
+
+// Step advances the simulated clock by realDelta scaled by the multiplier.
+// Like Advance it runs the timers that became due. It lets a render or
+// replay loop drive the simulation in lockstep without reading the real
+// clock. A later SetMultiplier only affects the following steps.
+func (tk *TimeKeeper) Step(realDelta time.Duration) {
+	tk.mutex.Lock()
+	d := time.Duration(float64(realDelta) * tk.multiplier)
+	tk.mutex.Unlock()
+
+	tk.Advance(d)
+}
+
+// fireDue runs every timer whose deadline has been reached, earliest first
+func (tk *TimeKeeper) fireDue() {
+	tk.mutex.Lock()
+	tk.firing++
+	tk.mutex.Unlock()
+	defer func() {
+		tk.mutex.Lock()
+		tk.firing--
+		tk.mutex.Unlock()
+	}()
+
+	for {
+		tk.mutex.Lock()
+		now := tk.now()
+		var next *SimTimer
+		for st := range tk.timers {
+			if st.when.After(now) {
+				continue
+			}
+			if next == nil || st.when.Before(next.when) ||
+				(st.when.Equal(next.when) && st.seq < next.seq) {
+				next = st
+			}
+		}
+		if next == nil {
+			tk.mutex.Unlock()
+			return
+		}
+		tk.stopTimer(next)
+		tk.mutex.Unlock()
+
+		next.f(now)
+	}
+}
+
+// NextDeadline returns the earliest deadline among the pending timers,
+// including those behind tickers, OnPeriod, OnSchedule and At. It reports
+// false when no timer is pending. In manual mode advancing to it runs the
+// next due callbacks.
+func (tk *TimeKeeper) NextDeadline() (deadline time.Time, ok bool) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	for st := range tk.timers {
+		if !ok || st.when.Before(deadline) {
+			deadline, ok = st.when, true
+		}
+	}
+	return deadline, ok
+}
@@ -0,0 +1,67 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualAdvance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+	if !tk.IsManual() {
+		t.Fatal("IsManual() = false")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if now := tk.Now(); !now.Equal(start) {
+		t.Errorf("Now() moved to %v without Advance", now)
+	}
+
+	timer := tk.NewTimer(time.Hour)
+	tk.Advance(30 * time.Minute)
+	select {
+	case <-timer.C:
+		t.Fatal("timer fired before its deadline")
+	default:
+	}
+	tk.Advance(45 * time.Minute)
+	if got, want := tk.Now(), start.Add(75*time.Minute); !got.Equal(want) {
+		t.Errorf("Now() = %v, want %v", got, want)
+	}
+	select {
+	case at := <-timer.C:
+		if !at.Equal(start.Add(75 * time.Minute)) {
+			t.Errorf("timer fired at %v, want %v", at, start.Add(75*time.Minute))
+		}
+	default:
+		t.Error("timer did not fire once Advance passed its deadline")
+	}
+}
+
+func TestManualStep(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+	tk.SetMultiplier(60)
+	tk.Step(time.Second)
+	if got, want := tk.Now(), start.Add(time.Minute); !got.Equal(want) {
+		t.Errorf("Now() after Step(1s) at 60x = %v, want %v", got, want)
+	}
+}
+
+func TestStepReplay(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+	tk.SetMultiplier(1000)
+
+	var want time.Duration
+	for i, delta := range []time.Duration{16 * time.Millisecond, 17 * time.Millisecond, time.Nanosecond, 0, 33 * time.Millisecond} {
+		if i == 3 {
+			tk.SetMultiplier(2)
+		}
+		tk.Step(delta)
+		want += time.Duration(float64(delta) * tk.Multiplier())
+	}
+	if got := tk.Now().Sub(start); got != want {
+		t.Errorf("replayed %v of simulated time, want %v", got, want)
+	}
+}
@@ -0,0 +1,59 @@
+package timewarp
+
+// Gauge is a metric that can be set to arbitrary values. A Prometheus gauge
+// satisfies it.
+type Gauge interface {
+	Set(value float64)
+}
+
+// Counter is a metric that only goes up, a Prometheus counter satisfies it
+type Counter interface {
+	Inc()
+}
+
+// Metrics holds the metrics updated by a TimeKeeper, nil fields are skipped
+type Metrics struct {
+	// Multiplier is set on every speed change
+	Multiplier Gauge
+	// SimulatedTime is set to the simulated unix time on every call to Now
+	SimulatedTime Gauge
+	// SyncSuccess counts successful calls to Synchronize
+	SyncSuccess Counter
+	// SyncFailure counts failed calls to Synchronize
+	SyncFailure Counter
+}
+
+// SetMetrics sets the metrics the TimeKeeper reports to. The multiplier
+// gauge is set right away.
+func (tk *TimeKeeper) SetMetrics(m Metrics) {
+	tk.mutex.Lock()
+	tk.metrics = m
+	tk.timeGauge = m.SimulatedTime
+	tk.publish()
+	multiplier := tk.multiplier
+	tk.mutex.Unlock()
+
+	if m.Multiplier != nil {
+		m.Multiplier.Set(multiplier)
+	}
+}
+
+// observeMultiplier reports a speed change
+func (m Metrics) observeMultiplier(multiplier float64) {
+	if m.Multiplier != nil {
+		m.Multiplier.Set(multiplier)
+	}
+}
+
+// observeSync counts the outcome of a sync
+func (m Metrics) observeSync(err error) {
+	if err != nil {
+		if m.SyncFailure != nil {
+			m.SyncFailure.Inc()
+		}
+		return
+	}
+	if m.SyncSuccess != nil {
+		m.SyncSuccess.Inc()
+	}
+}
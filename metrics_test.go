@@ -0,0 +1,81 @@
+package timewarp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMetric records what a TimeKeeper reports to it
+type fakeMetric struct {
+	mutex sync.Mutex
+	value float64
+	count int
+}
+
+func (m *fakeMetric) Set(value float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.value = value
+}
+
+func (m *fakeMetric) Inc() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.count++
+}
+
+func (m *fakeMetric) get() (float64, int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.value, m.count
+}
+
+func TestMetricsSyncCounters(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"simulated_time":"2030-01-01T00:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	success, failure := &fakeMetric{}, &fakeMetric{}
+	tk.SetMetrics(Metrics{SyncSuccess: success, SyncFailure: failure})
+
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := tk.Synchronize(srv.URL + "/x\x7f"); err == nil {
+		t.Fatal("Synchronize with a bad url succeeded")
+	}
+	if err := tk.Synchronize("http://127.0.0.1:1/"); err == nil {
+		t.Fatal("Synchronize with an unreachable url succeeded")
+	}
+
+	if _, n := success.get(); n != 1 {
+		t.Errorf("SyncSuccess = %d, want 1", n)
+	}
+	if _, n := failure.get(); n != 2 {
+		t.Errorf("SyncFailure = %d, want 2", n)
+	}
+}
+
+func TestMetricsMultiplier(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	gauge := &fakeMetric{}
+	tk.SetMetrics(Metrics{Multiplier: gauge})
+	if v, _ := gauge.get(); v != 1 {
+		t.Errorf("Multiplier gauge after SetMetrics = %v, want 1", v)
+	}
+
+	tk.SetMultiplier(4)
+	if v, _ := gauge.get(); v != 4 {
+		t.Errorf("Multiplier gauge after SetMultiplier = %v, want 4", v)
+	}
+
+	tk.Reset()
+	if v, _ := gauge.get(); v != 1 {
+		t.Errorf("Multiplier gauge after Reset = %v, want 1", v)
+	}
+}
@@ -0,0 +1,94 @@
+package timewarp
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers read by Middleware when time headers are allowed
+const (
+	// HeaderNow sets the simulated time of the request in RFC 3339 format
+	HeaderNow = "X-Timewarp-Now"
+	// HeaderMultiplier sets the multiplier of the request
+	HeaderMultiplier = "X-Timewarp-Multiplier"
+)
+
+// MiddlewareOption configures Middleware
+type MiddlewareOption func(*middlewareConfig)
+
+// middlewareConfig holds the settings collected from middleware options
+type middlewareConfig struct {
+	allowHeaders bool
+}
+
+// AllowTimeHeaders makes Middleware honor the HeaderNow and
+// HeaderMultiplier request headers. Only enable it where clients may warp
+// time, such as in test and staging deployments.
+func AllowTimeHeaders() MiddlewareOption {
+	return func(c *middlewareConfig) {
+		c.allowHeaders = true
+	}
+}
+
+// Middleware stores a TimeKeeper in the context of every request for
+// FromContext, by default base. With AllowTimeHeaders a request carrying
+// HeaderNow or HeaderMultiplier gets its own TimeKeeper starting from the
+// given time and running at the given multiplier. Each defaults to the
+// current value of base. Invalid headers are rejected with 400.
+func Middleware(base *TimeKeeper, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	var c middlewareConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tk := base
+			if c.allowHeaders {
+				var err error
+				tk, err = requestTimeKeeper(base, r.Header)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), tk)))
+		})
+	}
+}
+
+// requestTimeKeeper returns the TimeKeeper described by the time headers,
+// or base if there are none
+func requestTimeKeeper(base *TimeKeeper, header http.Header) (*TimeKeeper, error) {
+	nowValue := header.Get(HeaderNow)
+	multiplierValue := header.Get(HeaderMultiplier)
+	if nowValue == "" && multiplierValue == "" {
+		return base, nil
+	}
+
+	start := base.Now()
+	if nowValue != "" {
+		t, err := time.Parse(time.RFC3339Nano, nowValue)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s header: %w", ErrInvalidTimeFormat, HeaderNow, err)
+		}
+		start = t
+	}
+
+	multiplier := base.Multiplier()
+	if multiplierValue != "" {
+		m, err := strconv.ParseFloat(multiplierValue, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s header: %w", ErrInvalidMultiplier, HeaderMultiplier, err)
+		}
+		multiplier = m
+	}
+
+	return NewWithOptions(
+		WithStartTime(start),
+		WithMultiplier(multiplier),
+		WithLocation(base.Location()),
+	)
+}
@@ -0,0 +1,69 @@
+package timewarp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	base, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got *TimeKeeper
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = FromContext(r.Context())
+	})
+
+	tests := []struct {
+		name       string
+		opts       []MiddlewareOption
+		header     map[string]string
+		status     int
+		year       int
+		multiplier float64
+	}{
+		{"ignored headers", nil, map[string]string{HeaderMultiplier: "10"}, http.StatusOK, 2024, 1},
+		{"no headers", []MiddlewareOption{AllowTimeHeaders()}, nil, http.StatusOK, 2024, 1},
+		{"now", []MiddlewareOption{AllowTimeHeaders()}, map[string]string{HeaderNow: "2030-05-01T00:00:00Z"}, http.StatusOK, 2030, 1},
+		{"multiplier", []MiddlewareOption{AllowTimeHeaders()}, map[string]string{HeaderMultiplier: "10"}, http.StatusOK, 2024, 10},
+		{"bad now", []MiddlewareOption{AllowTimeHeaders()}, map[string]string{HeaderNow: "tomorrow"}, http.StatusBadRequest, 0, 0},
+		{"bad multiplier", []MiddlewareOption{AllowTimeHeaders()}, map[string]string{HeaderMultiplier: "NaN"}, http.StatusBadRequest, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			Middleware(base, tt.opts...)(handler).ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if got == nil {
+				t.Fatal("no TimeKeeper in the request context")
+			}
+			if (tt.header == nil || tt.opts == nil) && got != base {
+				t.Error("the request did not get the base TimeKeeper")
+			}
+			if y := got.Now().Year(); y != tt.year {
+				t.Errorf("request clock is in %d, want %d", y, tt.year)
+			}
+			if m := got.Multiplier(); m != tt.multiplier {
+				t.Errorf("request multiplier = %v, want %v", m, tt.multiplier)
+			}
+		})
+	}
+	if m := base.Multiplier(); m != 1 || base.Now().Sub(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) > time.Second {
+		t.Error("a request changed the base TimeKeeper")
+	}
+}
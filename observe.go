@@ -0,0 +1,116 @@
+package timewarp
+
+import (
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ChangeKind identifies what changed in a ChangeEvent
+type ChangeKind int
+
+const (
+	// MultiplierChanged is reported by SetMultiplier
+	MultiplierChanged ChangeKind = iota
+	// TimeSet is reported by SetTime
+	TimeSet
+	// Synced is reported by a successful Synchronize
+	Synced
+	// Paused is reported by Pause
+	Paused
+	// Resumed is reported by Resume
+	Resumed
+	// Restarted is reported by Reset
+	Restarted
+)
+
+// String returns the name of the kind
+func (k ChangeKind) String() string {
+	switch k {
+	case MultiplierChanged:
+		return "MultiplierChanged"
+	case TimeSet:
+		return "TimeSet"
+	case Synced:
+		return "Synced"
+	case Paused:
+		return "Paused"
+	case Resumed:
+		return "Resumed"
+	case Restarted:
+		return "Restarted"
+	}
+	return "ChangeKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// ChangeEvent describes an external change to the simulation
+type ChangeEvent struct {
+	Kind          ChangeKind
+	OldMultiplier float64
+	NewMultiplier float64
+	OldTime       time.Time
+	NewTime       time.Time
+	At            time.Time
+}
+
+// OnChange registers f to be called after the speed or the time of the
+// simulation is changed or it is paused, resumed or reset. Observers are
+// called synchronously in the order they were registered, from the
+// goroutine making the change. No lock is held, so they may call back into
+// the TimeKeeper.
+func (tk *TimeKeeper) OnChange(f func(ChangeEvent)) {
+	tk.subscribe(f)
+}
+
+// observer is a registered change callback
+type observer struct {
+	id uint64
+	f  func(ChangeEvent)
+}
+
+// subscribe registers f and returns a function removing it again
+func (tk *TimeKeeper) subscribe(f func(ChangeEvent)) (unsubscribe func()) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	tk.observerSeq++
+	id := tk.observerSeq
+	tk.observers = append(tk.observers, observer{id: id, f: f})
+
+	return func() {
+		tk.mutex.Lock()
+		defer tk.mutex.Unlock()
+		// copy so that slices handed to notify stay untouched
+		tk.observers = slices.DeleteFunc(slices.Clone(tk.observers), func(o observer) bool {
+			return o.id == id
+		})
+	}
+}
+
+// change starts an event capturing the state before a change. The caller
+// must hold the mutex.
+func (tk *TimeKeeper) change(kind ChangeKind) ChangeEvent {
+	at := tk.realNow()
+	return ChangeEvent{
+		Kind:          kind,
+		OldMultiplier: tk.multiplier,
+		OldTime:       tk.simAt(at),
+		At:            at,
+	}
+}
+
+// commit completes an event with the state after a change, records it in
+// the history and returns the observers to notify. The caller must hold the
+// mutex.
+func (tk *TimeKeeper) commit(ev *ChangeEvent) []observer {
+	ev.NewMultiplier = tk.multiplier
+	ev.NewTime = tk.now()
+	tk.record(*ev)
+	return tk.observers
+}
+
+// notify calls observers with ev, the caller must not hold the mutex
+func notify(observers []observer, ev ChangeEvent) {
+	for _, o := range observers {
+		o.f(ev)
+	}
+}
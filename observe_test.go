@@ -0,0 +1,42 @@
+package timewarp
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestOnChange(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var order []string
+	var events []ChangeEvent
+	tk.OnChange(func(ev ChangeEvent) {
+		order = append(order, "first")
+		events = append(events, ev)
+		// observers run without the lock held
+		tk.Multiplier()
+	})
+	tk.OnChange(func(ChangeEvent) { order = append(order, "second") })
+
+	tk.SetMultiplier(10)
+	target := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk.SetTime(target)
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if ev := events[0]; ev.Kind != MultiplierChanged || ev.OldMultiplier != 1 || ev.NewMultiplier != 10 {
+		t.Errorf("first event = %+v, want MultiplierChanged from 1 to 10", ev)
+	}
+	if ev := events[1]; ev.Kind != TimeSet || ev.OldTime.Year() != 2024 || ev.NewTime.Sub(target) > time.Second {
+		t.Errorf("second event = %+v, want TimeSet to %v", ev, target)
+	}
+	want := []string{"first", "second", "first", "second"}
+	if !slices.Equal(order, want) {
+		t.Errorf("observers ran in order %v, want %v", order, want)
+	}
+}
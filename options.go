@@ -0,0 +1,116 @@
+package timewarp
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures a TimeKeeper created by NewWithOptions
+type Option func(*config)
+
+// config holds the settings collected from options
+type config struct {
+	startTime  time.Time
+	multiplier float64
+	location   *time.Location
+	monotonic  bool
+	positive   bool
+	nowFunc    func() time.Time
+}
+
+// WithStartTime sets the simulated instant the simulation starts at
+func WithStartTime(t time.Time) Option {
+	return func(c *config) {
+		c.startTime = t
+	}
+}
+
+// WithStartNow starts the simulation at the current wall-clock time. This
+// is the default.
+func WithStartNow() Option {
+	return func(c *config) {
+		c.startTime = time.Time{}
+	}
+}
+
+// WithMultiplier sets the time speed, the default is 1
+func WithMultiplier(multiplier float64) Option {
+	return func(c *config) {
+		c.multiplier = multiplier
+	}
+}
+
+// WithLocation sets the time zone of the simulation, the default is UTC
+func WithLocation(location *time.Location) Option {
+	return func(c *config) {
+		c.location = location
+	}
+}
+
+// WithMonotonic enables monotonic mode, see SetMonotonic
+func WithMonotonic() Option {
+	return func(c *config) {
+		c.monotonic = true
+	}
+}
+
+// WithPositiveMultiplier rejects multipliers of zero or below, both at
+// construction and in SetMultiplierChecked
+func WithPositiveMultiplier() Option {
+	return func(c *config) {
+		c.positive = true
+	}
+}
+
+// WithNowFunc sets the real clock driving the simulation, see SetNowFunc
+func WithNowFunc(now func() time.Time) Option {
+	return func(c *config) {
+		c.nowFunc = now
+	}
+}
+
+// NewWithOptions initializes a new TimeKeeper from the given options
+func NewWithOptions(opts ...Option) (*TimeKeeper, error) {
+	c := config{
+		multiplier: 1,
+		location:   time.UTC,
+		nowFunc:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if err := validateMultiplier(c.multiplier, c.positive); err != nil {
+		return nil, err
+	}
+
+	if c.nowFunc == nil {
+		c.nowFunc = time.Now
+	}
+
+	startRealTime := c.nowFunc()
+	startSimTime := c.startTime
+	if startSimTime.IsZero() {
+		startSimTime = startRealTime
+	}
+	startSimTime = startSimTime.In(c.location)
+
+	tk := &TimeKeeper{
+		view: view{
+			startRealTime: startRealTime,
+			startSimTime:  startSimTime,
+			multiplier:    c.multiplier,
+			location:      c.location,
+			monotonic:     c.monotonic,
+			nowFunc:       c.nowFunc,
+		},
+		initialSimTime:    startSimTime,
+		initialMultiplier: c.multiplier,
+		initialRealTime:   startRealTime,
+		requirePositive:   c.positive,
+		historySize:       defaultHistorySize,
+		timelineSize:      defaultTimelineSize,
+		httpClient:        &http.Client{Timeout: defaultSyncTimeout},
+	}
+	tk.publish()
+	return tk, nil
+}
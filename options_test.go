@@ -0,0 +1,50 @@
+package timewarp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewWithOptionsDefaults(t *testing.T) {
+	tk, err := NewWithOptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := tk.Multiplier(); m != 1 {
+		t.Errorf("default multiplier = %v, want 1", m)
+	}
+	if loc := tk.Location(); loc != time.UTC {
+		t.Errorf("default location = %v, want UTC", loc)
+	}
+	if d := time.Since(tk.Now()); d < -time.Millisecond || d > 10*time.Millisecond {
+		t.Errorf("default start is %v away from the real clock", d)
+	}
+}
+
+func TestNewWithOptions(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	tk, err := NewWithOptions(WithStartTime(start), WithMultiplier(2), WithLocation(tokyo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.Pause()
+	if m := tk.Multiplier(); m != 2 {
+		t.Errorf("Multiplier() = %v, want 2", m)
+	}
+	now := tk.Now()
+	if now.Location() != tokyo {
+		t.Errorf("Now() is in %v, want Asia/Tokyo", now.Location())
+	}
+	if d := now.Sub(start); d < 0 || d > 10*time.Millisecond {
+		t.Errorf("Now() = %v, want about %v", now, start)
+	}
+
+	if _, err := NewWithOptions(WithMultiplier(0), WithPositiveMultiplier()); !errors.Is(err, ErrInvalidMultiplier) {
+		t.Errorf("zero multiplier with WithPositiveMultiplier: err = %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package timewarp
+
+import (
+	"strconv"
+	"time"
+)
+
+// Period is a calendar period whose boundaries OnPeriod reports
+type Period int
+
+const (
+	// PeriodHour is the top of every hour on the local wall clock
+	PeriodHour Period = iota
+	// PeriodDay is every local midnight
+	PeriodDay
+)
+
+// String returns the name of the period
+func (p Period) String() string {
+	switch p {
+	case PeriodHour:
+		return "PeriodHour"
+	case PeriodDay:
+		return "PeriodDay"
+	}
+	return "Period(" + strconv.Itoa(int(p)) + ")"
+}
+
+// next returns the first boundary of p after t in the location of t
+func (p Period) next(t time.Time) time.Time {
+	switch p {
+	case PeriodDay:
+		// time.Date normalizes days lengthened or shortened by daylight
+		// saving and midnights they skip
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	default:
+		// step in absolute time so both passes through an hour repeated
+		// by daylight saving are reported
+		intoHour := time.Duration(t.Minute())*time.Minute +
+			time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+		return t.Add(time.Hour - intoHour)
+	}
+}
+
+// OnPeriod calls f with each boundary of period crossed by the simulated
+// clock in the location of the simulation, once per boundary and in order.
+// Under a high multiplier or after a jump forward every boundary passed is
+// reported. Calls run one at a time, from Advance in manual mode. The
+// returned function stops further calls. Boundaries are only reported as
+// the clock moves forward. After a jump backward reporting resumes once the
+// clock passes the last boundary reported again.
+func (tk *TimeKeeper) OnPeriod(period Period, f func(boundary time.Time)) (stop func()) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	stopped := false
+	st := &SimTimer{tk: tk, when: period.next(tk.now())}
+	st.f = func(time.Time) {
+		boundary := st.when
+		f(boundary)
+
+		tk.mutex.Lock()
+		defer tk.mutex.Unlock()
+		if stopped {
+			return
+		}
+		st.when = period.next(boundary)
+		tk.startTimer(st)
+	}
+	tk.startTimer(st)
+
+	return func() {
+		tk.mutex.Lock()
+		defer tk.mutex.Unlock()
+		stopped = true
+		tk.stopTimer(st)
+	}
+}
@@ -0,0 +1,59 @@
+package timewarp
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestOnPeriodDaily(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the second day is shortened to 23 hours by daylight saving
+	tk := NewManual(time.Date(2024, 3, 9, 12, 0, 0, 0, newYork))
+
+	var got []time.Time
+	stop := tk.OnPeriod(PeriodDay, func(boundary time.Time) { got = append(got, boundary) })
+	defer stop()
+
+	// three simulated days pass in a few large steps as they would at a
+	// high multiplier
+	for range 3 {
+		tk.Advance(24 * time.Hour)
+	}
+
+	want := []time.Time{
+		time.Date(2024, 3, 10, 0, 0, 0, 0, newYork),
+		time.Date(2024, 3, 11, 0, 0, 0, 0, newYork),
+		time.Date(2024, 3, 12, 0, 0, 0, 0, newYork),
+	}
+	if !slices.EqualFunc(got, want, time.Time.Equal) {
+		t.Errorf("OnPeriod(PeriodDay) boundaries = %v, want %v", got, want)
+	}
+}
+
+func TestOnPeriodRunning(t *testing.T) {
+	tk, err := NewWithOptions(
+		WithStartTime(time.Date(2024, 1, 1, 23, 59, 59, 0, time.UTC)),
+		WithMultiplier(1000),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	fired := make(chan time.Time, 1)
+	stop := tk.OnPeriod(PeriodDay, func(boundary time.Time) { fired <- boundary })
+	defer stop()
+
+	select {
+	case got := <-fired:
+		if want := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Errorf("boundary = %v, want %v", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnPeriod did not fire at 1000x")
+	}
+}
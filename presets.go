@@ -0,0 +1,75 @@
+package timewarp
+
+import (
+	"errors"
+	"slices"
+)
+
+// PresetSet steps the multiplier of a TimeKeeper through an ordered list of
+// named speeds, like the speed control of a media player. The position is
+// derived from the current multiplier, so changes made elsewhere are
+// followed. While a ramp is in progress, such as one started under
+// SetMaxAcceleration, the multiplier it heads for counts as current.
+type PresetSet struct {
+	tk      *TimeKeeper
+	presets []float64
+}
+
+// NewPresetSet binds the given multipliers to tk. They are sorted in
+// increasing order and duplicates are dropped. At least one is required and
+// each must be a valid multiplier.
+func NewPresetSet(tk *TimeKeeper, multipliers ...float64) (*PresetSet, error) {
+	if len(multipliers) == 0 {
+		return nil, errors.New("timewarp: no speed preset")
+	}
+	for _, m := range multipliers {
+		if err := validateMultiplier(m, false); err != nil {
+			return nil, err
+		}
+	}
+
+	presets := slices.Clone(multipliers)
+	slices.Sort(presets)
+	return &PresetSet{tk: tk, presets: slices.Compact(presets)}, nil
+}
+
+// Presets returns the multipliers of the set in increasing order
+func (p *PresetSet) Presets() []float64 {
+	return slices.Clone(p.presets)
+}
+
+// NextSpeed sets the multiplier to the first preset above the current one
+// and returns the resulting multiplier, at the last preset it stays there
+func (p *PresetSet) NextSpeed() float64 {
+	current := p.tk.targetMultiplier()
+	next := p.presets[len(p.presets)-1]
+	for _, m := range p.presets {
+		if m > current {
+			next = m
+			break
+		}
+	}
+	p.tk.SetMultiplier(next)
+	return p.tk.targetMultiplier()
+}
+
+// PrevSpeed sets the multiplier to the last preset below the current one
+// and returns the resulting multiplier, at the first preset it stays there
+func (p *PresetSet) PrevSpeed() float64 {
+	current := p.tk.targetMultiplier()
+	prev := p.presets[0]
+	for _, m := range slices.Backward(p.presets) {
+		if m < current {
+			prev = m
+			break
+		}
+	}
+	p.tk.SetMultiplier(prev)
+	return p.tk.targetMultiplier()
+}
+
+// CurrentPresetIndex returns the index of the preset equal to the current
+// multiplier, or -1 if the multiplier is not one of the presets
+func (p *PresetSet) CurrentPresetIndex() int {
+	return slices.Index(p.presets, p.tk.targetMultiplier())
+}
@@ -0,0 +1,71 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPresetSetClamps(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	p, err := NewPresetSet(tk, 10, 0.5, 2, 1, 5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []float64{2, 5, 10, 10, 10} {
+		if got := p.NextSpeed(); got != want {
+			t.Errorf("NextSpeed() = %v, want %v", got, want)
+		}
+	}
+	if got := p.CurrentPresetIndex(); got != 4 {
+		t.Errorf("CurrentPresetIndex() at the max = %d, want 4", got)
+	}
+
+	for _, want := range []float64{5, 2, 1, 0.5, 0.5} {
+		if got := p.PrevSpeed(); got != want {
+			t.Errorf("PrevSpeed() = %v, want %v", got, want)
+		}
+	}
+	if got := p.CurrentPresetIndex(); got != 0 {
+		t.Errorf("CurrentPresetIndex() at the min = %d, want 0", got)
+	}
+
+	tk.SetMultiplier(3)
+	if got := p.CurrentPresetIndex(); got != -1 {
+		t.Errorf("CurrentPresetIndex() off the presets = %d, want -1", got)
+	}
+	if got := p.NextSpeed(); got != 5 {
+		t.Errorf("NextSpeed() from 3 = %v, want 5", got)
+	}
+}
+
+func TestPresetSetUnderAcceleration(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	if err := tk.SetMaxAcceleration(1); err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPresetSet(tk, 1, 2, 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each call steps from the preset the previous one is easing into
+	for _, want := range []float64{2, 5, 10} {
+		if got := p.NextSpeed(); got != want {
+			t.Errorf("NextSpeed() = %v, want %v", got, want)
+		}
+	}
+	if got := p.CurrentPresetIndex(); got != 3 {
+		t.Errorf("CurrentPresetIndex() while easing into 10 = %d, want 3", got)
+	}
+	if m := tk.Multiplier(); m >= 2 {
+		t.Errorf("Multiplier() = %v right away, want it still easing from 1", m)
+	}
+	if got := p.PrevSpeed(); got != 5 {
+		t.Errorf("PrevSpeed() = %v, want 5", got)
+	}
+}
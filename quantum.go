@@ -0,0 +1,31 @@
+package timewarp
+
+import "time"
+
+// SetQuantum makes Now advance in steps of d of simulated time. Readings
+// are floored onto a grid of multiples of d counted from the simulated time
+// when SetQuantum is called, so every reader within the same step sees the
+// same value. The grid stays in place across speed changes. A d of zero or
+// below disables quantization.
+func (tk *TimeKeeper) SetQuantum(d time.Duration) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	tk.quantum = max(d, 0)
+	tk.quantumBase = tk.now()
+	tk.publish()
+}
+
+// quantize floors t onto the quantum grid
+func (v *view) quantize(t time.Time) time.Time {
+	if v.quantum <= 0 {
+		return t
+	}
+
+	offset := t.Sub(v.quantumBase)
+	steps := offset / v.quantum
+	if offset < 0 && offset%v.quantum != 0 {
+		steps--
+	}
+	return v.quantumBase.Add(steps * v.quantum).In(v.location)
+}
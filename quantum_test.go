@@ -0,0 +1,31 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuantum(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+	const frame = 20 * time.Millisecond
+	tk.SetQuantum(frame)
+
+	first := tk.Now()
+	for i := 0; i < 5; i++ {
+		tk.Advance(frame / 10)
+		if got := tk.Now(); !got.Equal(first) {
+			t.Fatalf("Now() within one frame moved from %v to %v", first, got)
+		}
+	}
+	tk.Advance(frame / 2)
+	if got, want := tk.Now(), start.Add(frame); !got.Equal(want) {
+		t.Errorf("Now() in the next frame = %v, want %v", got, want)
+	}
+
+	tk.Advance(frame / 4)
+	tk.SetQuantum(0)
+	if got, want := tk.Now(), start.Add(frame+frame/4); !got.Equal(want) {
+		t.Errorf("Now() without a quantum = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package timewarp
+
+import "time"
+
+// rampStep is the real interval between multiplier updates of a ramp
+const rampStep = 10 * time.Millisecond
+
+// RampMultiplier eases the multiplier linearly from its current value to
+// target over the given real duration. The clock stays continuous at every
+// step. A later SetMultiplier or RampMultiplier supersedes the ramp.
+// Observers are notified once when the ramp completes. Targets outside the
+// range set with SetMultiplierRange are clamped like in SetMultiplier. A
+// ramp faster than the limit set with SetMaxAcceleration is stretched to
+// respect it. It returns an error wrapping ErrInvalidMultiplier for an
+// invalid target and ErrClosed after Close. In manual mode no real time
+// passes to ramp over, so it returns ErrManual unless over is zero or
+// below, which sets the multiplier right away like SetMultiplier.
+func (tk *TimeKeeper) RampMultiplier(target float64, over time.Duration) error {
+	if over <= 0 {
+		return tk.updateMultiplier(target, true)
+	}
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	if tk.closed {
+		return ErrClosed
+	}
+	if tk.manual {
+		return ErrManual
+	}
+	if err := validateMultiplier(target, tk.requirePositive); err != nil {
+		return err
+	}
+	target = tk.clampMultiplier(target)
+	tk.startRamp(target, max(over, tk.accelerationTime(target)))
+	return nil
+}
+
+// startRamp supersedes any running ramp with one to target over the given
+// real duration. The caller must hold the mutex.
+func (tk *TimeKeeper) startRamp(target float64, over time.Duration) {
+	tk.rampGen++
+	tk.rampTarget = target
+	tk.rampTargetGen = tk.rampGen
+	ev := tk.change(MultiplierChanged)
+	go tk.ramp(tk.rampGen, ev, target, over)
+}
+
+// ramp runs the steps of a ramp until it completes or is superseded
+func (tk *TimeKeeper) ramp(gen uint64, ev ChangeEvent, target float64, over time.Duration) {
+	ticker := time.NewTicker(rampStep)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+
+		tk.mutex.Lock()
+		if tk.rampGen != gen {
+			tk.mutex.Unlock()
+			return
+		}
+		elapsed := tk.realNow().Sub(ev.At)
+		tk.timelineRamp = gen
+		if elapsed >= over {
+			tk.setMultiplier(target)
+			tk.timelineRamp = 0
+			tk.rampTargetGen = 0
+			observers := tk.commit(&ev)
+			metrics := tk.metrics
+			tk.mutex.Unlock()
+
+			metrics.observeMultiplier(target)
+			notify(observers, ev)
+			return
+		}
+		fraction := float64(elapsed) / float64(over)
+		multiplier := ev.OldMultiplier + (target-ev.OldMultiplier)*fraction
+		tk.setMultiplier(multiplier)
+		tk.timelineRamp = 0
+		metrics := tk.metrics
+		tk.mutex.Unlock()
+
+		metrics.observeMultiplier(multiplier)
+	}
+}
+
+// targetMultiplier returns the multiplier a ramp in progress is heading
+// for, or the current multiplier when none is running
+func (tk *TimeKeeper) targetMultiplier() float64 {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	if tk.rampTargetGen != 0 && tk.rampTargetGen == tk.rampGen {
+		return tk.rampTarget
+	}
+	return tk.multiplier
+}
@@ -0,0 +1,72 @@
+package timewarp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRampMultiplier(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	changes := make(chan ChangeEvent, 10)
+	tk.OnChange(func(ev ChangeEvent) { changes <- ev })
+
+	tk.RampMultiplier(101, 200*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	if m := tk.Multiplier(); m <= 1 || m >= 101 {
+		t.Errorf("Multiplier() midway through the ramp = %v, want between 1 and 101", m)
+	}
+
+	select {
+	case ev := <-changes:
+		if ev.OldMultiplier != 1 || ev.NewMultiplier != 101 {
+			t.Errorf("ramp reported %v to %v, want 1 to 101", ev.OldMultiplier, ev.NewMultiplier)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the ramp did not complete")
+	}
+	if m := tk.Multiplier(); m != 101 {
+		t.Errorf("Multiplier() after the ramp = %v, want 101", m)
+	}
+	select {
+	case ev := <-changes:
+		t.Errorf("unexpected event %+v", ev)
+	default:
+	}
+}
+
+func TestRampSuperseded(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	tk.RampMultiplier(100, 100*time.Millisecond)
+	tk.SetMultiplier(5)
+	time.Sleep(150 * time.Millisecond)
+	if m := tk.Multiplier(); m != 5 {
+		t.Errorf("Multiplier() = %v, the superseded ramp kept running", m)
+	}
+}
+
+func TestRampMultiplierManual(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if err := tk.RampMultiplier(10, time.Second); !errors.Is(err, ErrManual) {
+		t.Errorf("RampMultiplier on a manual clock = %v, want ErrManual", err)
+	}
+	if m := tk.Multiplier(); m != 1 {
+		t.Errorf("Multiplier() after a rejected ramp = %v, want 1", m)
+	}
+	if err := tk.RampMultiplier(10, 0); err != nil {
+		t.Errorf("RampMultiplier over zero on a manual clock = %v, want nil", err)
+	}
+	if m := tk.Multiplier(); m != 10 {
+		t.Errorf("Multiplier() after an instant ramp = %v, want 10", m)
+	}
+}
@@ -0,0 +1,37 @@
+package timewarp
+
+import (
+	"iter"
+	"time"
+)
+
+// day is the step length Range treats as a civil day
+const day = 24 * time.Hour
+
+// Range yields the simulated instants from, from+step and so on up to and
+// including to in the location of the simulation. Nothing is yielded for a
+// step of zero or below. A step that is a whole number of days advances by
+// calendar days, so the wall-clock time of day is kept across daylight
+// saving transitions. Other steps advance in absolute time.
+func (tk *TimeKeeper) Range(from, to time.Time, step time.Duration) iter.Seq[time.Time] {
+	location := tk.Location()
+
+	return func(yield func(time.Time) bool) {
+		if step <= 0 {
+			return
+		}
+
+		from := from.In(location)
+		for i := 0; ; i++ {
+			var t time.Time
+			if step%day == 0 {
+				t = from.AddDate(0, 0, i*int(step/day))
+			} else {
+				t = from.Add(time.Duration(i) * step)
+			}
+			if t.After(to) || !yield(t) {
+				return
+			}
+		}
+	}
+}
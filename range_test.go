@@ -0,0 +1,63 @@
+package timewarp
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRange(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk := NewManual(time.Date(2024, 3, 1, 0, 0, 0, 0, newYork))
+	from := time.Date(2024, 3, 9, 12, 0, 0, 0, newYork)
+
+	tests := []struct {
+		name string
+		to   time.Time
+		step time.Duration
+		want []time.Time
+	}{
+		{
+			name: "to is included",
+			to:   from.Add(time.Hour),
+			step: 30 * time.Minute,
+			want: []time.Time{from, from.Add(30 * time.Minute), from.Add(time.Hour)},
+		},
+		{
+			name: "to is not exceeded",
+			to:   from.Add(50 * time.Minute),
+			step: 20 * time.Minute,
+			want: []time.Time{from, from.Add(20 * time.Minute), from.Add(40 * time.Minute)},
+		},
+		{
+			name: "days keep the wall clock across daylight saving",
+			to:   from.AddDate(0, 0, 2),
+			step: 24 * time.Hour,
+			want: []time.Time{
+				from,
+				time.Date(2024, 3, 10, 12, 0, 0, 0, newYork),
+				time.Date(2024, 3, 11, 12, 0, 0, 0, newYork),
+			},
+		},
+		{name: "zero step", to: from.Add(time.Hour), step: 0},
+		{name: "negative step", to: from.Add(time.Hour), step: -time.Minute},
+		{name: "to before from", to: from.Add(-time.Hour), step: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Collect(tk.Range(from, tt.to, tt.step))
+			if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Errorf("Range = %v, want %v", got, tt.want)
+			}
+			for _, ts := range got {
+				if ts.Location() != newYork {
+					t.Errorf("Range yielded %v outside America/New_York", ts)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package timewarp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket refilled on the simulated timeline of a
+// TimeKeeper. A limit of five actions per simulated minute allows five
+// times a second of real time at a multiplier of sixty.
+type RateLimiter struct {
+	tk     *TimeKeeper
+	mutex  sync.Mutex
+	n      int
+	per    time.Duration
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter allowing n actions per simulated
+// duration per. The bucket starts full and holds at most n tokens. It
+// panics if n or per is not positive.
+func NewRateLimiter(tk *TimeKeeper, n int, per time.Duration) *RateLimiter {
+	if n <= 0 || per <= 0 {
+		panic("timewarp: non-positive rate for NewRateLimiter")
+	}
+	return &RateLimiter{
+		tk:     tk,
+		n:      n,
+		per:    per,
+		tokens: float64(n),
+		last:   tk.Now(),
+	}
+}
+
+// Allow takes a token if one is available and reports whether it did
+func (l *RateLimiter) Allow() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.refill(l.tk.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Wait blocks until a token is available and takes it, following speed
+// changes while waiting. It returns ctx.Err() if ctx is done first or
+// ErrClosed if the TimeKeeper is closed.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mutex.Lock()
+		now := l.tk.Now()
+		l.refill(now)
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mutex.Unlock()
+			return nil
+		}
+		wait := time.Duration((1 - l.tokens) * float64(l.per) / float64(l.n))
+		l.mutex.Unlock()
+
+		if err := l.tk.WaitUntil(ctx, now.Add(wait)); err != nil {
+			return err
+		}
+	}
+}
+
+// Tokens returns the number of tokens currently available. It may be
+// fractional while the bucket refills.
+func (l *RateLimiter) Tokens() float64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.refill(l.tk.Now())
+	return l.tokens
+}
+
+// refill adds the tokens earned since the last refill. A clock that moved
+// backward earns nothing. The caller must hold the mutex.
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = min(float64(l.n), l.tokens+float64(elapsed)*float64(l.n)/float64(l.per))
+	}
+	l.last = now
+}
@@ -0,0 +1,77 @@
+package timewarp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterScalesWithMultiplier(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newClock := func(multiplier float64) *TimeKeeper {
+		tk, err := NewWithOptions(
+			WithMultiplier(multiplier),
+			WithNowFunc(func() time.Time { return real }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tk
+	}
+	// five per simulated minute is one token every twelve simulated seconds
+	nominal := NewRateLimiter(newClock(1), 5, time.Minute)
+	fast := NewRateLimiter(newClock(10), 5, time.Minute)
+
+	for _, l := range []*RateLimiter{nominal, fast} {
+		for i := 0; i < 5; i++ {
+			if !l.Allow() {
+				t.Fatalf("Allow() %d on a full bucket = false", i)
+			}
+		}
+		if l.Allow() {
+			t.Fatal("Allow() on an empty bucket = true")
+		}
+	}
+
+	real = real.Add(1200 * time.Millisecond)
+	if !fast.Allow() {
+		t.Error("at 10x no token refilled after 1.2 real seconds")
+	}
+	if got := nominal.Tokens(); got < 0.099 || got > 0.101 {
+		t.Errorf("at 1x Tokens() after 1.2 real seconds = %v, want 0.1", got)
+	}
+	if nominal.Allow() {
+		t.Error("at 1x a token refilled after 1.2 real seconds")
+	}
+
+	real = real.Add(10800 * time.Millisecond)
+	if !nominal.Allow() {
+		t.Error("at 1x no token refilled after 12 real seconds")
+	}
+	if got := fast.Tokens(); got != 5 {
+		t.Errorf("at 10x Tokens() after 12 real seconds = %v, want the full 5", got)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	tk, err := NewWithOptions(WithMultiplier(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	// one token per simulated 500ms is one per 50ms of real time at 10x
+	l := NewRateLimiter(tk, 1, 500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	started := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed < 90*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("three Waits took %v, want about 100ms", elapsed)
+	}
+}
@@ -0,0 +1,55 @@
+package timewarp
+
+import "time"
+
+// reconcileRate bounds the correction applied by ReconcileFromSync as a
+// fraction of the multiplier
+const reconcileRate = 0.1
+
+// ReconcileFromSync folds a sync received by a client into the local clock
+// without visible jumps. The remote time is advanced by half of rtt. The
+// remaining offset is corrected gradually at no more than a tenth of the
+// multiplier, faster or slower, so a display driven by Now stays smooth and
+// settles on the remote clock without overshooting. A non-zero remoteMult
+// replaces the multiplier right away. Each call supersedes the correction
+// of the previous one. With a multiplier of zero or below the clock jumps
+// to the remote time.
+func (tk *TimeKeeper) ReconcileFromSync(remoteSim time.Time, remoteMult float64, rtt time.Duration) error {
+	tk.mutex.Lock()
+	if tk.closed {
+		tk.mutex.Unlock()
+		return ErrClosed
+	}
+	if remoteMult != 0 {
+		if err := validateMultiplier(remoteMult, tk.requirePositive); err != nil {
+			tk.mutex.Unlock()
+			return err
+		}
+	}
+
+	ev := tk.change(Synced)
+	tk.rebase()
+	if remoteMult != 0 {
+		tk.rampGen++
+		tk.multiplier = remoteMult
+	}
+	target := remoteSim.Add(time.Duration(float64(rtt/2) * tk.multiplier))
+	offset := target.Sub(tk.startSimTime)
+	if offset < 0 {
+		offset = -offset
+	}
+	window := time.Duration(float64(offset) / (reconcileRate * tk.multiplier))
+	tk.slewTo(target, window)
+	tk.lastSync = tk.realNow()
+	tk.lastSyncRTT = rtt
+	tk.lastSyncUpdated = true
+	observers := tk.commit(&ev)
+	metrics := tk.metrics
+	tk.mutex.Unlock()
+
+	if remoteMult != 0 {
+		metrics.observeMultiplier(remoteMult)
+	}
+	notify(observers, ev)
+	return nil
+}
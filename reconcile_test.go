@@ -0,0 +1,43 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconcileConverges(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	real := base
+	remoteStart := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	remoteAt := func() time.Time { return remoteStart.Add(real.Sub(base)) }
+
+	// the local clock starts half a second behind the remote one
+	tk, err := NewWithOptions(
+		WithStartTime(remoteStart.Add(-500*time.Millisecond)),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	behind := 500 * time.Millisecond
+	for i := 0; i < 8; i++ {
+		if err := tk.ReconcileFromSync(remoteAt(), 1, 0); err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < 4; j++ {
+			real = real.Add(250 * time.Millisecond)
+			offset := remoteAt().Sub(tk.Now())
+			if offset < 0 {
+				t.Fatalf("the local clock overshot by %v", -offset)
+			}
+			if offset > behind {
+				t.Fatalf("the offset grew from %v to %v", behind, offset)
+			}
+			behind = offset
+		}
+	}
+	if behind > time.Millisecond {
+		t.Errorf("the local clock is still %v behind", behind)
+	}
+}
@@ -0,0 +1,221 @@
+package timewarp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Schedule computes the occurrences of a recurring job on the simulated
+// timeline
+type Schedule interface {
+	// Next returns the first occurrence strictly after t
+	Next(t time.Time) time.Time
+}
+
+// dailySchedule occurs every day at a fixed wall-clock time
+type dailySchedule struct {
+	hour, minute int
+}
+
+// Daily returns a schedule occurring every day at hour:minute in the
+// location of the times it is given
+func Daily(hour, minute int) Schedule {
+	return dailySchedule{hour: hour, minute: minute}
+}
+
+// Next returns the first hour:minute strictly after t
+func (s dailySchedule) Next(t time.Time) time.Time {
+	next := time.Date(t.Year(), t.Month(), t.Day(), s.hour, s.minute, 0, 0, t.Location())
+	for !next.After(t) {
+		next = time.Date(next.Year(), next.Month(), next.Day()+1, s.hour, s.minute, 0, 0, t.Location())
+	}
+	return next
+}
+
+// everySchedule occurs at a fixed simulated interval
+type everySchedule struct {
+	d time.Duration
+}
+
+// Every returns a schedule occurring every d of simulated time. The
+// interval must be positive.
+func Every(d time.Duration) Schedule {
+	if d <= 0 {
+		panic("timewarp: non-positive interval for Every")
+	}
+	return everySchedule{d: d}
+}
+
+// Next returns t plus the interval
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.d)
+}
+
+// OnSchedule calls f with each occurrence of schedule on the simulated
+// clock without a Scheduler. Like OnPeriod it reports every occurrence
+// passed under a high multiplier or after a jump forward, in order. Calls
+// run one at a time, from Advance in manual mode. The returned function
+// stops further calls, and they end once the schedule has no next
+// occurrence.
+func (tk *TimeKeeper) OnSchedule(schedule Schedule, f func(occurrence time.Time)) (stop func()) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	stopped := false
+	st := &SimTimer{tk: tk, when: schedule.Next(tk.now())}
+	st.f = func(time.Time) {
+		occurrence := st.when
+		f(occurrence)
+
+		tk.mutex.Lock()
+		defer tk.mutex.Unlock()
+		next := schedule.Next(occurrence)
+		if stopped || next.IsZero() {
+			return
+		}
+		st.when = next
+		tk.startTimer(st)
+	}
+	if !st.when.IsZero() {
+		tk.startTimer(st)
+	}
+
+	return func() {
+		tk.mutex.Lock()
+		defer tk.mutex.Unlock()
+		stopped = true
+		tk.stopTimer(st)
+	}
+}
+
+// JobID identifies a job registered with a Scheduler
+type JobID int
+
+// job is a function run on a schedule
+type job struct {
+	id       JobID
+	schedule Schedule
+	f        func(time.Time)
+	next     time.Time
+}
+
+// Scheduler runs jobs on the simulated timeline of a TimeKeeper
+type Scheduler struct {
+	tk     *TimeKeeper
+	mutex  sync.Mutex
+	jobs   map[JobID]*job
+	nextID JobID
+	wake   chan struct{}
+}
+
+// NewScheduler returns a scheduler driven by the clock of tk, jobs only run
+// while Run is active
+func NewScheduler(tk *TimeKeeper) *Scheduler {
+	return &Scheduler{
+		tk:   tk,
+		jobs: make(map[JobID]*job),
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Add registers f to run at each occurrence of schedule after the current
+// simulated time. Each call receives the scheduled simulated instant. A job
+// whose schedule has no next occurrence is removed.
+func (s *Scheduler) Add(schedule Schedule, f func(time.Time)) JobID {
+	next := schedule.Next(s.tk.Now())
+
+	s.mutex.Lock()
+	s.nextID++
+	id := s.nextID
+	if !next.IsZero() {
+		s.jobs[id] = &job{id: id, schedule: schedule, f: f, next: next}
+	}
+	s.mutex.Unlock()
+
+	s.notify()
+	return id
+}
+
+// Remove unregisters a job, it returns false if the job is unknown
+func (s *Scheduler) Remove(id JobID) bool {
+	s.mutex.Lock()
+	_, ok := s.jobs[id]
+	delete(s.jobs, id)
+	s.mutex.Unlock()
+
+	s.notify()
+	return ok
+}
+
+// notify wakes the run loop so it picks up changes to the jobs
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run dispatches jobs until ctx is cancelled and returns the context error,
+// or ErrClosed once the TimeKeeper is closed. Jobs run one at a time on the
+// calling goroutine in order of their occurrences. Occurrences missed under
+// a high multiplier are all run.
+func (s *Scheduler) Run(ctx context.Context) error {
+	closing := s.tk.doneChan()
+	for {
+		s.mutex.Lock()
+		var next time.Time
+		for _, j := range s.jobs {
+			if next.IsZero() || j.next.Before(next) {
+				next = j.next
+			}
+		}
+		s.mutex.Unlock()
+
+		var due <-chan time.Time
+		if !next.IsZero() {
+			due = s.tk.At(next)
+		}
+
+		select {
+		case <-ctx.Done():
+			if due != nil {
+				s.tk.StopAt(due)
+			}
+			return ctx.Err()
+		case <-closing:
+			return ErrClosed
+		case <-s.wake:
+			if due != nil {
+				s.tk.StopAt(due)
+			}
+		case now := <-due:
+			s.runDue(now)
+		}
+	}
+}
+
+// runDue runs every occurrence up to now in chronological order
+func (s *Scheduler) runDue(now time.Time) {
+	for {
+		s.mutex.Lock()
+		var first *job
+		for _, j := range s.jobs {
+			if !j.next.After(now) && (first == nil || j.next.Before(first.next)) {
+				first = j
+			}
+		}
+		if first == nil {
+			s.mutex.Unlock()
+			return
+		}
+		at := first.next
+		first.next = first.schedule.Next(at)
+		if first.next.IsZero() {
+			delete(s.jobs, first.id)
+		}
+		s.mutex.Unlock()
+
+		first.f(at)
+	}
+}
@@ -0,0 +1,68 @@
+package timewarp
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSchedulerDropsExhaustedSchedule(t *testing.T) {
+	tk, err := NewWithOptions(WithStartTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), WithMultiplier(360000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	s := NewScheduler(tk)
+
+	never, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Add(never, func(at time.Time) { t.Errorf("job with no occurrence ran at %v", at) })
+	var hourly atomic.Int32
+	s.Add(Every(time.Hour), func(time.Time) { hourly.Add(1) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	deadline := time.Now().Add(time.Second)
+	for hourly.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := hourly.Load(); got != 3 {
+		t.Fatalf("hourly job ran %d times, want 3", got)
+	}
+}
+
+func TestSchedulerDailyOverAWeek(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(WithStartTime(start), WithMultiplier(3600000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	s := NewScheduler(tk)
+
+	var runs []time.Time
+	s.Add(Daily(9, 0), func(at time.Time) { runs = append(runs, at) })
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan error)
+	go func() { ran <- s.Run(ctx) }()
+
+	if err := tk.WaitUntil(context.Background(), start.AddDate(0, 0, 7)); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	<-ran
+
+	if len(runs) != 7 {
+		t.Fatalf("daily job ran %d times over a week, want 7", len(runs))
+	}
+	for i, at := range runs {
+		if want := start.AddDate(0, 0, i).Add(9 * time.Hour); !at.Equal(want) {
+			t.Errorf("run %d at %v, want %v", i, at, want)
+		}
+	}
+}
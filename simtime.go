@@ -0,0 +1,97 @@
+package timewarp
+
+import (
+	"context"
+	"time"
+)
+
+// SimTime is an instant on the simulated timeline. It wraps time.Time so
+// the compiler keeps simulated and real instants apart. Converting between
+// them always goes through a TimeKeeper with FromReal and ToReal, and Time
+// unwraps the value for use with the standard library.
+type SimTime struct {
+	t time.Time
+}
+
+// FromReal returns the simulated instant tk reads at the real instant t
+func FromReal(tk *TimeKeeper, t time.Time) SimTime {
+	return SimTime{tk.RealToSim(t)}
+}
+
+// ToReal returns the real instant at which tk reads s, see SimToReal
+func (s SimTime) ToReal(tk *TimeKeeper) time.Time {
+	return tk.SimToReal(s.t)
+}
+
+// Time returns the simulated instant as a plain time.Time
+func (s SimTime) Time() time.Time {
+	return s.t
+}
+
+// Add returns the simulated instant d later
+func (s SimTime) Add(d time.Duration) SimTime {
+	return SimTime{s.t.Add(d)}
+}
+
+// Sub returns the simulated duration s-u
+func (s SimTime) Sub(u SimTime) time.Duration {
+	return s.t.Sub(u.t)
+}
+
+// Before reports whether s is before u
+func (s SimTime) Before(u SimTime) bool {
+	return s.t.Before(u.t)
+}
+
+// After reports whether s is after u
+func (s SimTime) After(u SimTime) bool {
+	return s.t.After(u.t)
+}
+
+// Equal reports whether s and u are the same instant
+func (s SimTime) Equal(u SimTime) bool {
+	return s.t.Equal(u.t)
+}
+
+// IsZero reports whether s is the zero instant
+func (s SimTime) IsZero() bool {
+	return s.t.IsZero()
+}
+
+// Format formats s like time.Time.Format
+func (s SimTime) Format(layout string) string {
+	return s.t.Format(layout)
+}
+
+// String formats s like time.Time.String
+func (s SimTime) String() string {
+	return s.t.String()
+}
+
+// SimNow is like Now but returns a SimTime
+func (tk *TimeKeeper) SimNow() SimTime {
+	return SimTime{tk.Now()}
+}
+
+// SimAt is like At for a SimTime. The channel receives the simulated time
+// and can be cancelled with StopAt.
+func (tk *TimeKeeper) SimAt(s SimTime) <-chan time.Time {
+	return tk.At(s.t)
+}
+
+// RealDuration is the counterpart of Duration for simulated instants. It
+// returns the real time the clock takes to go from from to to at the
+// current multiplier. It is negative when the clock never gets there at
+// this speed, and zero while paused, frozen or in manual mode.
+func (tk *TimeKeeper) RealDuration(from, to SimTime) time.Duration {
+	v := tk.loadView()
+	if v.paused || v.manual || v.multiplier == 0 {
+		return 0
+	}
+	return time.Duration(float64(to.t.Sub(from.t)) / v.multiplier)
+}
+
+// SimWaitUntil is like WaitUntil for a SimTime
+func (tk *TimeKeeper) SimWaitUntil(ctx context.Context, s SimTime) error {
+	return tk.WaitUntil(ctx, s.t)
+}
@@ -0,0 +1,49 @@
+package timewarp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSimTime(t *testing.T) {
+	tk, err := NewWithOptions(WithStartTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)), WithMultiplier(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	now := tk.SimNow()
+	later := now.Add(10 * time.Second)
+	if got := tk.RealDuration(now, later); got != 100*time.Millisecond {
+		t.Errorf("RealDuration of 10 simulated seconds at x100 = %v, want 100ms", got)
+	}
+	if d := later.Sub(now); d != 10*time.Second {
+		t.Errorf("Sub = %v, want 10s", d)
+	}
+
+	real := time.Now()
+	if back := FromReal(tk, FromReal(tk, real).ToReal(tk)); !back.Equal(FromReal(tk, real)) {
+		t.Errorf("FromReal(ToReal(x)) = %v, want %v", back, FromReal(tk, real))
+	}
+
+	target := tk.SimNow().Add(5 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tk.SimWaitUntil(ctx, target); err != nil {
+		t.Fatal(err)
+	}
+	if tk.SimNow().Before(target) {
+		t.Errorf("SimWaitUntil returned before %v", target)
+	}
+	select {
+	case <-tk.SimAt(target):
+	case <-time.After(time.Second):
+		t.Error("SimAt of a past instant did not fire")
+	}
+
+	tk.Pause()
+	if got := tk.RealDuration(now, later); got != 0 {
+		t.Errorf("RealDuration while paused = %v, want 0", got)
+	}
+}
@@ -0,0 +1,187 @@
+package timewarp
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// simEvent is a function registered with a Simulation
+type simEvent struct {
+	at  time.Time
+	seq uint64
+	f   func()
+}
+
+// Simulation runs events on the simulated timeline of a TimeKeeper until an
+// end time. On a manual TimeKeeper the clock jumps from one event to the
+// next, so a run takes no real time. Otherwise events wait for the clock to
+// reach them at its current speed.
+type Simulation struct {
+	tk     *TimeKeeper
+	mutex  sync.Mutex
+	events []simEvent
+	seq    uint64
+	end    time.Time
+	wake   chan struct{}
+}
+
+// NewSimulation returns a simulation driven by the clock of tk, events only
+// run while Run is active
+func NewSimulation(tk *TimeKeeper) *Simulation {
+	return &Simulation{
+		tk:   tk,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// SetEnd makes Run return once the simulated clock reaches end. Events at
+// end still run and later ones are left pending. The zero time removes the
+// end.
+func (s *Simulation) SetEnd(end time.Time) {
+	s.mutex.Lock()
+	s.end = end
+	s.mutex.Unlock()
+
+	s.notify()
+}
+
+// AddEvent registers f to run once the simulated clock reaches at. Events
+// at the same instant run in the order they were added, and an event in the
+// simulated past runs right away. It may be called from a running event.
+func (s *Simulation) AddEvent(at time.Time, f func()) {
+	s.mutex.Lock()
+	s.seq++
+	s.events = append(s.events, simEvent{at: at, seq: s.seq, f: f})
+	s.mutex.Unlock()
+
+	s.notify()
+}
+
+// Pending returns the number of events that have not run yet
+func (s *Simulation) Pending() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.events)
+}
+
+// notify wakes the run loop so it picks up changes to the events
+func (s *Simulation) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run dispatches events in order of their simulated instants on the calling
+// goroutine. It returns nil once the end set with SetEnd is reached or, on
+// a manual TimeKeeper without an end, once no event is left. It returns
+// ctx.Err() if ctx is done first and ErrClosed if the TimeKeeper is closed.
+func (s *Simulation) Run(ctx context.Context) error {
+	closing := s.tk.doneChan()
+	manual := s.tk.IsManual()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		s.mutex.Lock()
+		next, ok := s.first()
+		end := s.end
+		s.mutex.Unlock()
+
+		if ok && (end.IsZero() || !next.at.After(end)) {
+			reached, err := s.reach(ctx, closing, manual, next.at)
+			if err != nil {
+				return err
+			}
+			if !reached {
+				continue
+			}
+			s.mutex.Lock()
+			ev, ok := s.pop(s.tk.Now())
+			s.mutex.Unlock()
+			if ok {
+				ev.f()
+			}
+			continue
+		}
+
+		if end.IsZero() {
+			if manual {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-closing:
+				return ErrClosed
+			case <-s.wake:
+				continue
+			}
+		}
+		reached, err := s.reach(ctx, closing, manual, end)
+		if err != nil || reached {
+			return err
+		}
+	}
+}
+
+// reach brings the simulated clock to t, advancing a manual clock or
+// waiting for the running one. It returns false without an error if the
+// wait was interrupted by a change to the events.
+func (s *Simulation) reach(ctx context.Context, closing <-chan struct{}, manual bool, t time.Time) (bool, error) {
+	if manual {
+		select {
+		case <-closing:
+			return false, ErrClosed
+		default:
+		}
+		if d := t.Sub(s.tk.Now()); d > 0 {
+			s.tk.Advance(d)
+		}
+		return true, nil
+	}
+
+	c := s.tk.At(t)
+	select {
+	case <-c:
+		return true, nil
+	case <-ctx.Done():
+		s.tk.StopAt(c)
+		return false, ctx.Err()
+	case <-closing:
+		return false, ErrClosed
+	case <-s.wake:
+		s.tk.StopAt(c)
+		return false, nil
+	}
+}
+
+// first returns the earliest pending event, the caller must hold the mutex
+func (s *Simulation) first() (simEvent, bool) {
+	var first simEvent
+	ok := false
+	for _, ev := range s.events {
+		if !ok || ev.at.Before(first.at) || (ev.at.Equal(first.at) && ev.seq < first.seq) {
+			first, ok = ev, true
+		}
+	}
+	return first, ok
+}
+
+// pop removes and returns the earliest event due at now. The caller must
+// hold the mutex.
+func (s *Simulation) pop(now time.Time) (simEvent, bool) {
+	first, ok := s.first()
+	if !ok || first.at.After(now) {
+		return simEvent{}, false
+	}
+	for i, ev := range s.events {
+		if ev.seq == first.seq {
+			s.events = append(s.events[:i], s.events[i+1:]...)
+			break
+		}
+	}
+	return first, true
+}
@@ -0,0 +1,73 @@
+package timewarp
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSimulationRunsInOrder(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+	sim := NewSimulation(tk)
+
+	var got []string
+	record := func(name string, want time.Time) func() {
+		return func() {
+			if now := tk.Now(); !now.Equal(want) {
+				t.Errorf("%s ran at %v, want %v", name, now, want)
+			}
+			got = append(got, name)
+		}
+	}
+	// added out of order, run by simulated instant
+	sim.AddEvent(start.Add(3*time.Hour), record("close", start.Add(3*time.Hour)))
+	sim.AddEvent(start.Add(time.Hour), record("open", start.Add(time.Hour)))
+	sim.AddEvent(start.Add(2*time.Hour), record("lunch", start.Add(2*time.Hour)))
+
+	if err := sim.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"open", "lunch", "close"}; !slices.Equal(got, want) {
+		t.Errorf("events ran in order %v, want %v", got, want)
+	}
+	if n := sim.Pending(); n != 0 {
+		t.Errorf("Pending() = %d, want 0", n)
+	}
+}
+
+func TestSimulationEnd(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(WithStartTime(start), WithMultiplier(360000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	sim := NewSimulation(tk)
+
+	ran := make(chan time.Time, 3)
+	for i := 1; i <= 3; i++ {
+		at := start.Add(time.Duration(i) * time.Hour)
+		sim.AddEvent(at, func() { ran <- at })
+	}
+	sim.SetEnd(start.Add(2 * time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sim.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	close(ran)
+	var got []time.Time
+	for at := range ran {
+		got = append(got, at)
+	}
+	want := []time.Time{start.Add(time.Hour), start.Add(2 * time.Hour)}
+	if !slices.EqualFunc(got, want, time.Time.Equal) {
+		t.Errorf("events before the end ran at %v, want %v", got, want)
+	}
+	if n := sim.Pending(); n != 1 {
+		t.Errorf("Pending() = %d, want 1", n)
+	}
+}
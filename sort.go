@@ -0,0 +1,38 @@
+package timewarp
+
+import (
+	"slices"
+	"time"
+)
+
+// Event is an entry of a timeline mixing real and simulated instants
+type Event struct {
+	// Time is the instant of the event
+	Time time.Time
+	// Sim marks Time as an instant of the simulated clock rather than the
+	// wall clock
+	Sim bool
+	// Data is carried along for the caller
+	Data any
+}
+
+// SortByRealTime sorts events in place by the wall-clock instant they
+// happen at. Simulated instants are mapped with SimToReal from a single
+// reading of the anchors, so the whole slice lands on one timeline. Events
+// at the same instant keep their order. Simulated instants that cannot be
+// mapped because the clock is paused, manual or frozen sort first.
+func SortByRealTime(tk *TimeKeeper, events []Event) {
+	tk.mutex.Lock()
+	v := tk.view
+	tk.mutex.Unlock()
+
+	realTime := func(ev Event) time.Time {
+		if ev.Sim {
+			return v.realAt(ev.Time)
+		}
+		return ev.Time
+	}
+	slices.SortStableFunc(events, func(a, b Event) int {
+		return realTime(a).Compare(realTime(b))
+	})
+}
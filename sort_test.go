@@ -0,0 +1,38 @@
+package timewarp
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSortByRealTime(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sim := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(sim),
+		WithMultiplier(60),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// at 60x a simulated hour is a real minute
+	events := []Event{
+		{Time: real.Add(2 * time.Minute), Data: "real 2m"},
+		{Time: sim.Add(time.Hour), Sim: true, Data: "sim 1h"},
+		{Time: real.Add(30 * time.Second), Data: "real 30s"},
+		{Time: sim.Add(30 * time.Minute), Sim: true, Data: "sim 30m"},
+		{Time: sim.Add(3 * time.Hour), Sim: true, Data: "sim 3h"},
+	}
+	SortByRealTime(tk, events)
+
+	var got []any
+	for _, ev := range events {
+		got = append(got, ev.Data)
+	}
+	if want := []any{"real 30s", "sim 30m", "sim 1h", "real 2m", "sim 3h"}; !slices.Equal(got, want) {
+		t.Errorf("SortByRealTime order = %v, want %v", got, want)
+	}
+}
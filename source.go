@@ -0,0 +1,116 @@
+package timewarp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxSyncBody caps the size of a response body read by Synchronize
+const maxSyncBody = 1 << 20
+
+// maxErrorSnippet caps the part of an error response quoted in the error
+const maxErrorSnippet = 256
+
+// TimeSource provides the authoritative simulated time for SynchronizeFrom.
+// Fetch returns the simulated time and optionally the multiplier. A
+// multiplier of zero leaves the current speed unchanged.
+type TimeSource interface {
+	Fetch(ctx context.Context) (time.Time, float64, error)
+}
+
+// HTTPTimeSource reads the simulated time from a JSON API. It is the source
+// used by Synchronize.
+type HTTPTimeSource struct {
+	// URL is the endpoint queried with GET
+	URL string
+	// Client sends the request. A client with a default timeout is used
+	// when it is nil.
+	Client *http.Client
+	// Config describes the payload
+	Config SyncConfig
+	// ETag is sent in If-None-Match when set and is updated from the ETag
+	// of successful responses
+	ETag string
+	// AllowedHosts, when not empty, lists the hosts URL may point to,
+	// compared without the port and ignoring case
+	AllowedHosts []string
+}
+
+// validateURL checks that the URL of s can be fetched before any request
+// is made
+func (s *HTTPTimeSource) validateURL() error {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSyncURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: %q must use http or https", ErrInvalidSyncURL, s.URL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%w: %q has no host", ErrInvalidSyncURL, s.URL)
+	}
+	if len(s.AllowedHosts) > 0 && !slices.ContainsFunc(s.AllowedHosts, func(host string) bool {
+		return strings.EqualFold(host, u.Hostname())
+	}) {
+		return fmt.Errorf("%w: host %q is not allowed", ErrInvalidSyncURL, u.Hostname())
+	}
+	return nil
+}
+
+// Fetch queries the API. It returns ErrNotModified for a 304 response to a
+// conditional request. Other responses outside 2xx and bodies larger than 1
+// MiB are rejected. An error wrapping ErrInvalidSyncURL is returned without
+// sending anything if URL is not an absolute http or https URL or its host
+// is not in AllowedHosts.
+func (s *HTTPTimeSource) Fetch(ctx context.Context) (time.Time, float64, error) {
+	if err := s.validateURL(); err != nil {
+		return time.Time{}, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	if s.ETag != "" {
+		req.Header.Set("If-None-Match", s.ETag)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: defaultSyncTimeout}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && s.ETag != "" {
+		return time.Time{}, 0, ErrNotModified
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorSnippet))
+		return time.Time{}, 0, fmt.Errorf("timewarp: unexpected status %s: %q", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSyncBody+1))
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	if len(body) > maxSyncBody {
+		return time.Time{}, 0, fmt.Errorf("timewarp: response body exceeds %d bytes", maxSyncBody)
+	}
+
+	payload, err := s.Config.parse(body)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	s.ETag = resp.Header.Get("ETag")
+	return payload.simTime, payload.multiplier, nil
+}
@@ -0,0 +1,74 @@
+package timewarp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSynchronizeBadResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{"not found", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "no clock here", http.StatusNotFound)
+		}, `404 Not Found: "no clock here"`},
+		{"oversized body", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"simulated_time":"` + strings.Repeat("x", maxSyncBody) + `"}`))
+		}, "exceeds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			tk, err := New("2024-01-01", "00:00", "UTC", 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tk.Synchronize(srv.URL)
+			if !errors.Is(err, ErrSyncFailed) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Synchronize = %v, want ErrSyncFailed mentioning %q", err, tt.want)
+			}
+			if !tk.LastSync().IsZero() {
+				t.Error("the failed sync was recorded")
+			}
+		})
+	}
+}
+
+func TestSynchronizeFromSource(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+
+	remote := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := tk.SynchronizeFrom(context.Background(), fakeSource{simTime: remote, multiplier: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if got := tk.Now(); got.Sub(remote) < 0 || got.Sub(remote) > time.Millisecond {
+		t.Errorf("Now() after sync = %v, want %v", got, remote)
+	}
+	if m := tk.Multiplier(); m != 3 {
+		t.Errorf("Multiplier() after sync = %v, want 3", m)
+	}
+
+	synced := tk.Now()
+	if err := tk.SynchronizeFrom(context.Background(), fakeSource{err: ErrNotModified}); err != nil {
+		t.Errorf("SynchronizeFrom a source not modified = %v", err)
+	}
+	if got := tk.Now(); !got.Equal(synced) || tk.LastSyncUpdated() {
+		t.Errorf("a source not modified moved the clock to %v", got)
+	}
+
+	failure := errors.New("source offline")
+	err := tk.SynchronizeFrom(context.Background(), fakeSource{err: failure})
+	if !errors.Is(err, ErrSyncFailed) || !errors.Is(err, failure) {
+		t.Errorf("SynchronizeFrom a failing source = %v, want ErrSyncFailed wrapping the failure", err)
+	}
+}
@@ -0,0 +1,59 @@
+package timewarp
+
+import "strconv"
+
+// Speed classifies how the simulated clock runs compared to real time
+type Speed int
+
+const (
+	// Frozen means the multiplier is zero so the simulated clock stands
+	// still
+	Frozen Speed = iota
+	// Reversed means the multiplier is negative so the simulated clock
+	// runs backwards
+	Reversed
+	// Slower means the simulated clock runs forward slower than real time
+	Slower
+	// RealTime means the multiplier is exactly 1
+	RealTime
+	// Faster means the simulated clock runs faster than real time
+	Faster
+)
+
+// String returns the name of the speed
+func (s Speed) String() string {
+	switch s {
+	case Frozen:
+		return "Frozen"
+	case Reversed:
+		return "Reversed"
+	case Slower:
+		return "Slower"
+	case RealTime:
+		return "RealTime"
+	case Faster:
+		return "Faster"
+	}
+	return "Speed(" + strconv.Itoa(int(s)) + ")"
+}
+
+// speedOf classifies a multiplier
+func speedOf(multiplier float64) Speed {
+	switch {
+	case multiplier < 0:
+		return Reversed
+	case multiplier == 0:
+		return Frozen
+	case multiplier < 1:
+		return Slower
+	case multiplier == 1:
+		return RealTime
+	}
+	return Faster
+}
+
+// Speed classifies the current multiplier. It only looks at the multiplier
+// so a paused simulation keeps reporting the speed it resumes at.
+func (tk *TimeKeeper) Speed() Speed {
+	return speedOf(tk.Multiplier())
+}
@@ -0,0 +1,26 @@
+package timewarp
+
+import "testing"
+
+func TestSpeed(t *testing.T) {
+	tests := []struct {
+		multiplier float64
+		want       Speed
+	}{
+		{-2, Reversed},
+		{0, Frozen},
+		{0.5, Slower},
+		{1, RealTime},
+		{1.0001, Faster},
+		{1000, Faster},
+	}
+	for _, tt := range tests {
+		tk, err := New("2024-01-01", "00:00", "UTC", tt.multiplier)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tk.Speed(); got != tt.want {
+			t.Errorf("Speed() at %v = %v, want %v", tt.multiplier, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package timewarp
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// speedRange is a time-of-day range with its multiplier
+type speedRange struct {
+	from, to   time.Duration
+	multiplier float64
+}
+
+// contains reports whether the offset from midnight falls in the range,
+// ranges ending before they start wrap around midnight
+func (r speedRange) contains(offset time.Duration) bool {
+	if r.from < r.to {
+		return offset >= r.from && offset < r.to
+	}
+	return offset >= r.from || offset < r.to
+}
+
+// SpeedSchedule maps simulated times of day to multipliers, such as real
+// time during the day and ten times faster overnight
+type SpeedSchedule struct {
+	mutex    sync.Mutex
+	fallback float64
+	ranges   []speedRange
+}
+
+// NewSpeedSchedule returns a schedule running at fallback outside the
+// ranges added to it
+func NewSpeedSchedule(fallback float64) (*SpeedSchedule, error) {
+	if err := validateMultiplier(fallback, false); err != nil {
+		return nil, err
+	}
+	return &SpeedSchedule{fallback: fallback}, nil
+}
+
+// Add runs the clock at multiplier from from until to, both given as
+// "15:04" on the local wall clock. The end is excluded, and a range ending
+// before it starts wraps around midnight. The first range added wins where
+// ranges overlap.
+func (s *SpeedSchedule) Add(from, to string, multiplier float64) error {
+	fromOffset, err := parseTimeOfDay(from)
+	if err != nil {
+		return err
+	}
+	toOffset, err := parseTimeOfDay(to)
+	if err != nil {
+		return err
+	}
+	if fromOffset == toOffset {
+		return errors.New("timewarp: empty speed range")
+	}
+	if err := validateMultiplier(multiplier, false); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ranges = append(s.ranges, speedRange{from: fromOffset, to: toOffset, multiplier: multiplier})
+	return nil
+}
+
+// MultiplierAt returns the multiplier scheduled at t, read on the wall
+// clock of the location of t
+func (s *SpeedSchedule) MultiplierAt(t time.Time) float64 {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, r := range s.ranges {
+		if r.contains(offset) {
+			return r.multiplier
+		}
+	}
+	return s.fallback
+}
+
+// next returns the first range boundary strictly after t
+func (s *SpeedSchedule) next(t time.Time) time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var next time.Time
+	for _, r := range s.ranges {
+		for _, offset := range []time.Duration{r.from, r.to} {
+			at := Daily(int(offset/time.Hour), int(offset%time.Hour/time.Minute)).Next(t)
+			if next.IsZero() || at.Before(next) {
+				next = at
+			}
+		}
+	}
+	return next
+}
+
+// ApplySpeedSchedule sets the multiplier scheduled for the current
+// simulated time right away. It sets it again each time the simulated clock
+// crosses a boundary of schedule in the location of the simulation. Like
+// OnPeriod, boundaries are followed as the clock moves forward. A change to
+// the multiplier made elsewhere holds until the next boundary. Ranges added
+// later apply from the next boundary. The returned function stops the
+// schedule.
+func (tk *TimeKeeper) ApplySpeedSchedule(schedule *SpeedSchedule) (stop func()) {
+	tk.SetMultiplier(schedule.MultiplierAt(tk.Now()))
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	stopped := false
+	st := &SimTimer{tk: tk}
+	st.f = func(time.Time) {
+		boundary := st.when
+		tk.SetMultiplier(schedule.MultiplierAt(boundary))
+
+		tk.mutex.Lock()
+		defer tk.mutex.Unlock()
+		if stopped {
+			return
+		}
+		tk.armSchedule(st, schedule, boundary)
+	}
+	tk.armSchedule(st, schedule, tk.now())
+
+	return func() {
+		tk.mutex.Lock()
+		defer tk.mutex.Unlock()
+		stopped = true
+		tk.stopTimer(st)
+	}
+}
+
+// armSchedule starts st at the first boundary of schedule after t. Nothing
+// is armed for a schedule without ranges. The caller must hold the mutex.
+func (tk *TimeKeeper) armSchedule(st *SimTimer, schedule *SpeedSchedule, t time.Time) {
+	next := schedule.next(t.In(tk.location))
+	if next.IsZero() {
+		return
+	}
+	st.when = next
+	tk.startTimer(st)
+}
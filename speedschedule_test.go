@@ -0,0 +1,54 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplySpeedSchedule(t *testing.T) {
+	schedule, err := NewSpeedSchedule(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schedule.Add("22:00", "06:00", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := schedule.Add("12:00", "13:00", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+	stop := tk.ApplySpeedSchedule(schedule)
+	defer stop()
+
+	tests := []struct {
+		at   time.Duration
+		want float64
+	}{
+		{0, 1},
+		{2*time.Hour - time.Second, 1},
+		{2 * time.Hour, 10},
+		{4 * time.Hour, 10}, // across midnight
+		{10*time.Hour - time.Second, 10},
+		{10 * time.Hour, 1},
+		{16 * time.Hour, 2},
+		{17 * time.Hour, 1},
+		{26 * time.Hour, 10},
+	}
+	for _, tt := range tests {
+		if d := start.Add(tt.at).Sub(tk.Now()); d > 0 {
+			tk.Advance(d)
+		}
+		if got := tk.Multiplier(); got != tt.want {
+			t.Errorf("Multiplier() at %v = %v, want %v", tk.Now().Format("Jan 2 15:04:05"), got, tt.want)
+		}
+	}
+
+	// past 06:00 the schedule would be back at 1x
+	stop()
+	tk.Advance(10 * time.Hour)
+	if got := tk.Multiplier(); got != 10 {
+		t.Errorf("Multiplier() after stop = %v, want 10", got)
+	}
+}
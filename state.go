@@ -0,0 +1,206 @@
+package timewarp
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State is a serializable snapshot of a simulation, all fields are read
+// under a single lock so they describe the same instant
+type State struct {
+	StartSimTime time.Time `json:"start_sim_time"`
+	Now          time.Time `json:"now"`
+	Multiplier   float64   `json:"multiplier"`
+	Paused       bool      `json:"paused"`
+	Location     string    `json:"location"`
+	RealTime     time.Time `json:"real_time"`
+}
+
+// Snapshot captures the current state of the simulation
+func (tk *TimeKeeper) Snapshot() State {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	return State{
+		StartSimTime: tk.initialSimTime,
+		Now:          tk.current(),
+		Multiplier:   tk.multiplier,
+		Paused:       tk.paused,
+		Location:     zoneName(tk.location),
+		RealTime:     tk.realNow().Round(0),
+	}
+}
+
+// Restore creates a TimeKeeper continuing from the simulated time captured
+// in s, paused if s was. Reset on the result returns to the original start
+// time.
+func Restore(s State) (*TimeKeeper, error) {
+	return RestoreWithOptions(s, RestoreOptions{})
+}
+
+// RestoreOptions controls how RestoreWithOptions resumes a simulation
+type RestoreOptions struct {
+	// CatchUp advances the restored simulated time by the real time elapsed
+	// since s was captured times the saved multiplier, as if the simulation
+	// kept running while it was down. A paused state is never advanced.
+	// Otherwise the simulation continues from the saved time.
+	CatchUp bool
+	// NowFunc is the real clock of the restored TimeKeeper, also used to
+	// measure the downtime, it defaults to time.Now
+	NowFunc func() time.Time
+}
+
+// RestoreWithOptions is like Restore with explicit control over the real
+// time that passed since s was captured
+func RestoreWithOptions(s State, opts RestoreOptions) (*TimeKeeper, error) {
+	location, err := loadLocation(s.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	now := opts.NowFunc
+	if now == nil {
+		now = time.Now
+	}
+
+	start := s.Now
+	if opts.CatchUp && !s.Paused && !s.RealTime.IsZero() {
+		downtime := now().Sub(s.RealTime)
+		start = start.Add(time.Duration(float64(downtime) * s.Multiplier))
+	}
+
+	tk, err := NewWithOptions(
+		WithStartTime(start),
+		WithMultiplier(s.Multiplier),
+		WithLocation(location),
+		WithNowFunc(now),
+	)
+	if err != nil {
+		return nil, err
+	}
+	tk.initialSimTime = s.StartSimTime.In(location)
+	if s.Paused {
+		tk.Pause()
+	}
+
+	return tk, nil
+}
+
+// SaveToFile writes a Snapshot of the simulation to path as JSON. The data
+// goes to a temporary file in the same directory that is renamed into
+// place, so a crash never leaves a truncated checkpoint.
+func (tk *TimeKeeper) SaveToFile(path string) error {
+	data, err := json.Marshal(tk.Snapshot())
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadFromFile restores a simulation saved by SaveToFile, catchUp has the
+// meaning of RestoreOptions.CatchUp
+func LoadFromFile(path string, catchUp bool) (*TimeKeeper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return RestoreWithOptions(s, RestoreOptions{CatchUp: catchUp})
+}
+
+// timeKeeperJSON is the wire format of a TimeKeeper
+type timeKeeperJSON struct {
+	StartSimTime  time.Time `json:"start_sim_time"`
+	StartRealTime time.Time `json:"start_real_time"`
+	Multiplier    float64   `json:"multiplier"`
+	Timezone      string    `json:"timezone"`
+}
+
+// MarshalJSON encodes the anchors, multiplier and time zone of the
+// simulation. A paused simulation is encoded as resuming from its frozen
+// value.
+func (tk *TimeKeeper) MarshalJSON() ([]byte, error) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	data := timeKeeperJSON{
+		StartSimTime:  tk.startSimTime,
+		StartRealTime: tk.startRealTime.Round(0),
+		Multiplier:    tk.multiplier,
+		Timezone:      zoneName(tk.location),
+	}
+	if tk.paused {
+		data.StartSimTime = tk.pausedAt
+		data.StartRealTime = tk.realNow().Round(0)
+	}
+
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON restores a TimeKeeper encoded by MarshalJSON
+func (tk *TimeKeeper) UnmarshalJSON(b []byte) error {
+	var data timeKeeperJSON
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+
+	location, err := loadLocation(data.Timezone)
+	if err != nil {
+		return err
+	}
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	// the decoded real anchor has no monotonic reading, the clock is
+	// re-anchored on the real clock so later wall-clock steps do not move it
+	realTime := tk.realNow()
+	elapsedReal := realTime.Round(0).Sub(data.StartRealTime)
+	tk.location = location
+	tk.startSimTime = data.StartSimTime.Add(time.Duration(float64(elapsedReal) * data.Multiplier)).In(location)
+	tk.startRealTime = realTime
+	tk.multiplier = data.Multiplier
+	tk.initialSimTime = data.StartSimTime.In(location)
+	tk.initialMultiplier = tk.multiplier
+	tk.initialRealTime = realTime.Add(-elapsedReal)
+	tk.totalPaused = 0
+	tk.paused = false
+	// a ramp or slewed correction in flight belongs to the replaced state
+	tk.rampGen++
+	tk.slewRate = 0
+	tk.slewUntil = time.Time{}
+	if tk.httpClient == nil {
+		// a zero TimeKeeper gets the defaults of NewWithOptions
+		tk.httpClient = &http.Client{Timeout: defaultSyncTimeout}
+		tk.historySize = defaultHistorySize
+		tk.timelineSize = defaultTimelineSize
+	}
+	tk.changed()
+
+	return nil
+}
@@ -0,0 +1,331 @@
+package timewarp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestoreFixedZone(t *testing.T) {
+	for _, zone := range []*time.Location{
+		time.FixedZone("", 2*60*60),
+		time.FixedZone("CEST", 2*60*60),
+		time.FixedZone("", -(9*60*60 + 30*60)),
+	} {
+		start := time.Date(2024, 6, 1, 12, 0, 0, 0, zone)
+		tk := NewManual(start)
+
+		restored, err := Restore(tk.Snapshot())
+		if err != nil {
+			t.Fatalf("Restore in %q: %v", zone, err)
+		}
+		now := restored.Now()
+		if d := now.Sub(start); d < 0 || d > time.Second {
+			t.Errorf("restored Now() = %v, want %v", now, start)
+		}
+		name, offset := now.Zone()
+		wantName, wantOffset := start.Zone()
+		if name != wantName || offset != wantOffset {
+			t.Errorf("restored zone = %q %d, want %q %d", name, offset, wantName, wantOffset)
+		}
+	}
+}
+
+func TestUnmarshalJSONFixedZone(t *testing.T) {
+	start, err := time.Parse(time.RFC3339, "2024-06-01T12:00:00+02:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk := NewManual(start)
+
+	data, err := json.Marshal(tk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded TimeKeeper
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal %s: %v", data, err)
+	}
+	if _, offset := decoded.Now().Zone(); offset != 2*60*60 {
+		t.Errorf("decoded offset = %d, want %d", offset, 2*60*60)
+	}
+}
+
+func TestSnapshotRestoreRoundTrip(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "Europe/Paris", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(tk.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := tk.Now()
+	gap := 20 * time.Millisecond
+	time.Sleep(gap)
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := Restore(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := restored.Multiplier(); m != 60 {
+		t.Errorf("restored Multiplier() = %v, want 60", m)
+	}
+	if loc := restored.Location().String(); loc != "Europe/Paris" {
+		t.Errorf("restored Location() = %q, want Europe/Paris", loc)
+	}
+	// Restore continues from the captured time, the gap is not counted
+	if d := restored.Now().Sub(saved); d < -time.Second || d > time.Second {
+		t.Errorf("restored Now() is %v away from the snapshot", d)
+	}
+
+	restored.Pause()
+	restored.Reset()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, restored.Location())
+	if d := restored.Now().Sub(start); d < 0 || d > time.Second {
+		t.Errorf("Now() after Reset = %v, want about %v", restored.Now(), start)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "America/New_York", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(tk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded TimeKeeper
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if m := decoded.Multiplier(); m != 60 {
+		t.Errorf("decoded Multiplier() = %v, want 60", m)
+	}
+	if loc := decoded.Location().String(); loc != "America/New_York" {
+		t.Errorf("decoded Location() = %q, want America/New_York", loc)
+	}
+	// the decoded clock runs on the same timeline as the original
+	for i := 0; i < 3; i++ {
+		if d := decoded.Now().Sub(tk.Now()); d < -time.Second || d > time.Second {
+			t.Errorf("decoded Now() is %v away from the original", d)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestUnmarshalJSONCancelsTransients(t *testing.T) {
+	src, err := New("2024-01-01", "00:00", "UTC", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	tk.RampMultiplier(100, 100*time.Millisecond)
+	if err := json.Unmarshal(data, tk); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if m := tk.Multiplier(); m != 3 {
+		t.Errorf("Multiplier() after a ramp superseded by decoding = %v, want 3", m)
+	}
+
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	slewed, err := NewWithOptions(WithNowFunc(func() time.Time { return real }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slewed.SetSyncConfig(SyncConfig{Slew: time.Minute})
+	if err := slewed.SynchronizeFrom(context.Background(), fakeSource{simTime: real.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, slewed); err != nil {
+		t.Fatal(err)
+	}
+	if got := slewed.EffectiveRate(); got != 3 {
+		t.Errorf("EffectiveRate() after decoding over a slew = %v, want 3", got)
+	}
+}
+
+func TestUnmarshalJSONZeroValue(t *testing.T) {
+	data, err := json.Marshal(NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tk TimeKeeper
+	if err := json.Unmarshal(data, &tk); err != nil {
+		t.Fatal(err)
+	}
+
+	tk.SetMultiplier(2)
+	tk.SetMultiplier(3)
+	if got := len(tk.History()); got != 2 {
+		t.Errorf("len(History()) = %d after two changes, want 2", got)
+	}
+	if got := len(tk.Timeline()); got == 0 {
+		t.Error("Timeline() is empty after two changes")
+	}
+}
+
+func TestUnmarshalJSONMonotonicAnchor(t *testing.T) {
+	// time.Time cannot step its wall reading alone. The test checks instead
+	// that the decoded clock is anchored on a reading of the now function
+	// that keeps its monotonic part, which elapsed real time is measured on
+	// whatever the wall clock does.
+	real := time.Now()
+	now := func() time.Time { return real }
+	src, err := NewWithOptions(
+		WithStartTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+		WithMultiplier(60),
+		WithNowFunc(now),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := NewWithOptions(WithNowFunc(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if anchor := decoded.StartRealTime(); !strings.Contains(anchor.String(), " m=") {
+		t.Errorf("decoded real anchor %v has no monotonic reading", anchor)
+	}
+
+	before := decoded.Now()
+	real = real.Add(10 * time.Second)
+	if got := decoded.Now().Sub(before); got != 10*time.Minute {
+		t.Errorf("decoded Now() advanced %v in 10s at 60x, want 10m", got)
+	}
+}
+
+func TestSnapshotIsConsistent(t *testing.T) {
+	base := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(WithStartTime(base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	// every sync moves the clock to as many hours past base as its
+	// multiplier, a torn snapshot would mix two of them
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		hammer(1, 200*time.Millisecond, func(i int) {
+			m := float64(i%5 + 1)
+			tk.SynchronizeFrom(context.Background(), fakeSource{
+				simTime:    base.Add(time.Duration(m) * time.Hour),
+				multiplier: m,
+			})
+		})
+	}()
+	hammer(4, 200*time.Millisecond, func(int) {
+		s := tk.Snapshot()
+		if s.Multiplier == 1 && s.Now.Before(base.Add(time.Hour)) {
+			return
+		}
+		if hours := s.Now.Sub(base).Round(time.Hour) / time.Hour; float64(hours) != s.Multiplier {
+			t.Errorf("snapshot at %v has multiplier %v", s.Now, s.Multiplier)
+		}
+	})
+	<-done
+}
+
+func TestSaveLoadFile(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "Asia/Tokyo", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.Pause()
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := tk.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("SaveToFile left %d files behind, want 1", len(entries))
+	}
+
+	loaded, err := LoadFromFile(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := loaded.Now().Sub(tk.Now()); d < -time.Millisecond || d > time.Millisecond {
+		t.Errorf("loaded Now() is %v away from the saved clock", d)
+	}
+	if !loaded.IsPaused() || loaded.Multiplier() != 5 || loaded.Location().String() != "Asia/Tokyo" {
+		t.Errorf("loaded %v, want a paused clock at 5x in Asia/Tokyo", loaded)
+	}
+
+	if _, err := LoadFromFile(filepath.Join(t.TempDir(), "missing.json"), false); err == nil {
+		t.Error("LoadFromFile of a missing file succeeded")
+	}
+}
+
+func TestRestoreCatchUp(t *testing.T) {
+	saved := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	savedReal := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	s := State{
+		StartSimTime: saved.Add(-time.Hour),
+		Now:          saved,
+		Multiplier:   60,
+		Location:     "UTC",
+		RealTime:     savedReal,
+	}
+	// the simulation was down for ten real seconds
+	real := savedReal.Add(10 * time.Second)
+	now := func() time.Time { return real }
+
+	tk, err := RestoreWithOptions(s, RestoreOptions{CatchUp: true, NowFunc: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tk.Now(), saved.Add(10*time.Minute); !got.Equal(want) {
+		t.Errorf("Now() caught up = %v, want %v", got, want)
+	}
+
+	tk, err = RestoreWithOptions(s, RestoreOptions{NowFunc: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tk.Now(); !got.Equal(saved) {
+		t.Errorf("Now() reset = %v, want %v", got, saved)
+	}
+
+	s.Paused = true
+	tk, err = RestoreWithOptions(s, RestoreOptions{CatchUp: true, NowFunc: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tk.Now(); !got.Equal(saved) {
+		t.Errorf("paused Now() caught up to %v, want %v", got, saved)
+	}
+}
@@ -0,0 +1,72 @@
+package timewarp
+
+import (
+	"sync"
+	"time"
+)
+
+// Stopwatch measures intervals of simulated time. It follows speed changes
+// and does not count time the simulation spent paused.
+type Stopwatch struct {
+	tk      *TimeKeeper
+	mutex   sync.Mutex
+	running bool
+	start   time.Time
+	elapsed time.Duration
+}
+
+// NewStopwatch returns a stopped stopwatch on the clock of tk
+func (tk *TimeKeeper) NewStopwatch() *Stopwatch {
+	return &Stopwatch{tk: tk}
+}
+
+// Start starts or continues measuring. It does nothing if the stopwatch is
+// already running.
+func (sw *Stopwatch) Start() {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+	if sw.running {
+		return
+	}
+	sw.start = sw.tk.Now()
+	sw.running = true
+}
+
+// Stop stops measuring and keeps the elapsed time
+func (sw *Stopwatch) Stop() {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+	if !sw.running {
+		return
+	}
+	sw.elapsed += sw.tk.Now().Sub(sw.start)
+	sw.running = false
+}
+
+// Reset clears the elapsed time, a running stopwatch keeps running
+func (sw *Stopwatch) Reset() {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+	sw.elapsed = 0
+	if sw.running {
+		sw.start = sw.tk.Now()
+	}
+}
+
+// Elapsed returns the simulated time measured so far. It may be called
+// while the stopwatch is running.
+func (sw *Stopwatch) Elapsed() time.Duration {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+	if !sw.running {
+		return sw.elapsed
+	}
+	return sw.elapsed + sw.tk.Now().Sub(sw.start)
+}
+
+// Running reports whether the stopwatch is measuring
+func (sw *Stopwatch) Running() bool {
+	sw.mutex.Lock()
+	defer sw.mutex.Unlock()
+	return sw.running
+}
@@ -0,0 +1,42 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStopwatchAcrossSpeedChange(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(WithMultiplier(10), WithNowFunc(func() time.Time { return real }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sw := tk.NewStopwatch()
+	sw.Start()
+	real = real.Add(time.Second)
+	tk.SetMultiplier(60)
+	real = real.Add(time.Second)
+	if got := sw.Elapsed(); got != 70*time.Second {
+		t.Errorf("Elapsed() while running = %v, want 1m10s", got)
+	}
+
+	sw.Stop()
+	real = real.Add(time.Second)
+	if got := sw.Elapsed(); got != 70*time.Second {
+		t.Errorf("Elapsed() after Stop = %v, want 1m10s", got)
+	}
+	sw.Start()
+	tk.Pause()
+	real = real.Add(time.Hour)
+	tk.Resume()
+	real = real.Add(time.Second)
+	if got := sw.Elapsed(); got != 130*time.Second {
+		t.Errorf("Elapsed() across a pause = %v, want 2m10s", got)
+	}
+
+	sw.Reset()
+	if got := sw.Elapsed(); got != 0 || !sw.Running() {
+		t.Errorf("after Reset Elapsed() = %v and Running() = %v, want 0 and true", got, sw.Running())
+	}
+}
@@ -0,0 +1,93 @@
+package timewarp
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hammer runs f from n goroutines until d has passed
+func hammer(n int, d time.Duration, f func(i int)) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for g := 0; g < n; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ctx.Err() == nil; i++ {
+				f(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestStressReadersAndWriters(t *testing.T) {
+	source, err := New("2030-01-01", "00:00", "UTC", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(source.Handler())
+	defer srv.Close()
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	from := time.Now()
+
+	var wg sync.WaitGroup
+	run := func(n int, f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hammer(n, 300*time.Millisecond, f)
+		}()
+	}
+	run(8, func(int) {
+		tk.Now()
+		tk.Duration(from, time.Now())
+		tk.Snapshot()
+		tk.EffectiveRate()
+	})
+	run(2, func(i int) { tk.SetMultiplier(float64(i%5 + 1)) })
+	run(1, func(int) { tk.Reset() })
+	run(1, func(int) {
+		if err := tk.Synchronize(srv.URL); err != nil {
+			t.Errorf("Synchronize: %v", err)
+		}
+	})
+	wg.Wait()
+}
+
+func TestStressMonotonicNow(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	tk.SetMonotonic(true)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		hammer(1, 200*time.Millisecond, func(i int) {
+			tk.SetMultiplier(float64(i%3) - 1)
+			tk.SetTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		})
+	}()
+	hammer(8, 200*time.Millisecond, func(int) {
+		a := tk.Now()
+		b := tk.Now()
+		if b.Before(a) {
+			t.Errorf("Now() went backward from %v to %v", a, b)
+		}
+	})
+	wg.Wait()
+}
@@ -0,0 +1,376 @@
+package timewarp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultSyncTimeout bounds requests made by Synchronize unless a custom
+// client is set
+const defaultSyncTimeout = 10 * time.Second
+
+// SyncConfig describes the payload returned by the remote time API
+type SyncConfig struct {
+	// Field is the dot separated path of the JSON field holding the
+	// simulated time. It defaults to "simulated_time".
+	Field string
+	// Layout is the layout used to parse the simulated time, it defaults
+	// to time.RFC3339
+	Layout string
+	// MultiplierField is the dot separated path of the optional JSON field
+	// holding the multiplier. It defaults to "multiplier".
+	MultiplierField string
+	// Slew, when positive, corrects the simulated clock gradually over this
+	// real duration instead of jumping to the remote time
+	Slew time.Duration
+}
+
+// syncPayload is the data read from a time source
+type syncPayload struct {
+	simTime    time.Time
+	multiplier float64
+	// rtt is the round trip of the fetch. The remote time is assumed to
+	// have been read half way through it.
+	rtt time.Duration
+}
+
+// parse extracts the simulated time and the optional multiplier from a
+// response body
+func (c SyncConfig) parse(body []byte) (syncPayload, error) {
+	field := c.Field
+	if field == "" {
+		field = "simulated_time"
+	}
+	layout := c.Layout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	multiplierField := c.MultiplierField
+	if multiplierField == "" {
+		multiplierField = "multiplier"
+	}
+
+	var payload syncPayload
+
+	raw, ok, err := lookup(body, field)
+	if err != nil {
+		return payload, err
+	}
+	if !ok {
+		return payload, fmt.Errorf("timewarp: field %q not found in response", field)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return payload, err
+	}
+	payload.simTime, err = time.Parse(layout, value)
+	if err != nil {
+		return payload, err
+	}
+
+	raw, ok, err = lookup(body, multiplierField)
+	if err != nil {
+		return payload, err
+	}
+	if ok {
+		if err := json.Unmarshal(raw, &payload.multiplier); err != nil {
+			return payload, err
+		}
+	}
+
+	return payload, nil
+}
+
+// lookup walks a dot separated path through nested JSON objects
+func lookup(body []byte, path string) (json.RawMessage, bool, error) {
+	raw := json.RawMessage(body)
+	for _, key := range strings.Split(path, ".") {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return nil, false, err
+		}
+		value, ok := object[key]
+		if !ok {
+			return nil, false, nil
+		}
+		raw = value
+	}
+	return raw, true, nil
+}
+
+// SetSyncConfig sets how Synchronize reads the remote payload
+func (tk *TimeKeeper) SetSyncConfig(config SyncConfig) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	tk.syncConfig = config
+}
+
+// SetSyncAllowedHosts restricts the hosts Synchronize may query, calling it
+// without hosts lifts the restriction, see HTTPTimeSource.AllowedHosts
+func (tk *TimeKeeper) SetSyncAllowedHosts(hosts ...string) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	tk.syncHosts = slices.Clone(hosts)
+}
+
+// SetHTTPClient sets the client used by Synchronize. Passing nil restores
+// the default client.
+func (tk *TimeKeeper) SetHTTPClient(client *http.Client) {
+	if client == nil {
+		client = &http.Client{Timeout: defaultSyncTimeout}
+	}
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	tk.httpClient = client
+}
+
+// Synchronize fetches time from a remote API. The url must be an absolute
+// http or https URL. Other urls are rejected with an error wrapping
+// ErrInvalidSyncURL before any request is made.
+func (tk *TimeKeeper) Synchronize(url string) error {
+	return tk.SynchronizeContext(context.Background(), url)
+}
+
+// SynchronizeContext fetches time from a remote API. The request is bound
+// to ctx so it can be cancelled or given a deadline. A non-zero multiplier
+// in the payload replaces the current one. The ETag of the last response
+// from url is sent back, so a server answering 304 Not Modified leaves the
+// clock as it is, see LastSyncUpdated.
+func (tk *TimeKeeper) SynchronizeContext(ctx context.Context, url string) error {
+	tk.mutex.Lock()
+	src := &HTTPTimeSource{
+		URL:          url,
+		Client:       tk.httpClient,
+		Config:       tk.syncConfig,
+		ETag:         tk.syncETags[url],
+		AllowedHosts: tk.syncHosts,
+	}
+	tk.mutex.Unlock()
+
+	err := tk.SynchronizeFrom(ctx, src)
+
+	tk.mutex.Lock()
+	if tk.syncETags == nil {
+		tk.syncETags = make(map[string]string)
+	}
+	if src.ETag != "" {
+		tk.syncETags[url] = src.ETag
+	} else {
+		delete(tk.syncETags, url)
+	}
+	tk.mutex.Unlock()
+
+	return err
+}
+
+// SynchronizeFrom fetches time from src and applies it like
+// SynchronizeContext. The time taken by Fetch is treated as the round trip,
+// and the Slew of the SyncConfig set with SetSyncConfig applies.
+// ErrNotModified from Fetch counts as a successful sync leaving the clock
+// untouched. Other errors from Fetch are wrapped in ErrSyncFailed, except
+// ErrInvalidSyncURL.
+func (tk *TimeKeeper) SynchronizeFrom(ctx context.Context, src TimeSource) error {
+	tk.mutex.Lock()
+	closed := tk.closed
+	slew := tk.syncConfig.Slew
+	tk.mutex.Unlock()
+	if closed {
+		return ErrClosed
+	}
+
+	sent := time.Now()
+	simTime, multiplier, err := src.Fetch(ctx)
+	switch {
+	case errors.Is(err, ErrNotModified):
+		tk.mutex.Lock()
+		tk.lastSync = tk.realNow()
+		tk.lastSyncUpdated = false
+		tk.mutex.Unlock()
+		err = nil
+	case errors.Is(err, ErrInvalidSyncURL):
+	case err != nil:
+		err = fmt.Errorf("%w: %w", ErrSyncFailed, err)
+	default:
+		if err = tk.applySync(syncPayload{
+			simTime:    simTime,
+			multiplier: multiplier,
+			rtt:        time.Since(sent),
+		}, slew); err != nil {
+			err = fmt.Errorf("%w: remote %w", ErrSyncFailed, err)
+		}
+	}
+
+	tk.mutex.Lock()
+	metrics := tk.metrics
+	tk.mutex.Unlock()
+	metrics.observeSync(err)
+	if err != nil {
+		tk.logSyncFailure(err)
+	}
+
+	return err
+}
+
+// SynchronizeAny tries each url in order and stops at the first successful
+// sync. Every attempt is reported to the metrics. If all of them fail the
+// errors are joined. The context bounds the whole sequence, and no further
+// url is tried once it is done.
+func (tk *TimeKeeper) SynchronizeAny(ctx context.Context, urls ...string) error {
+	if len(urls) == 0 {
+		return errors.New("timewarp: no url to synchronize from")
+	}
+
+	var errs []error
+	for _, url := range urls {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		err := tk.SynchronizeContext(ctx, url)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", url, err))
+	}
+	return errors.Join(errs...)
+}
+
+// applySync moves the simulation to the state read from a time source,
+// correcting gradually over slew when it is positive. The remote time is
+// advanced by half the round trip at the new speed to account for latency.
+// A remote multiplier the TimeKeeper does not accept is rejected.
+func (tk *TimeKeeper) applySync(payload syncPayload, slew time.Duration) error {
+	tk.mutex.Lock()
+	if payload.multiplier != 0 {
+		if err := validateMultiplier(payload.multiplier, tk.requirePositive); err != nil {
+			tk.mutex.Unlock()
+			return err
+		}
+	}
+	ev := tk.change(Synced)
+	if payload.multiplier != 0 {
+		tk.rampGen++
+	}
+	multiplier := tk.multiplier
+	if payload.multiplier != 0 {
+		multiplier = payload.multiplier
+	}
+	payload.simTime = payload.simTime.Add(time.Duration(float64(payload.rtt/2) * multiplier))
+	if slew > 0 {
+		if payload.multiplier != 0 {
+			tk.rebase()
+			tk.multiplier = payload.multiplier
+		}
+		tk.slewTo(payload.simTime, slew)
+	} else {
+		if payload.multiplier != 0 {
+			tk.multiplier = payload.multiplier
+		}
+		tk.setTime(payload.simTime)
+	}
+	tk.lastSync = tk.realNow()
+	tk.lastSyncRTT = payload.rtt
+	tk.lastSyncUpdated = true
+	observers := tk.commit(&ev)
+	metrics := tk.metrics
+	tk.mutex.Unlock()
+
+	if payload.multiplier != 0 {
+		metrics.observeMultiplier(payload.multiplier)
+	}
+	notify(observers, ev)
+	return nil
+}
+
+// LastSync returns the real time of the last successful sync, or the zero
+// time if the TimeKeeper was never synchronized
+func (tk *TimeKeeper) LastSync() time.Time {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.lastSync
+}
+
+// LastSyncUpdated reports whether the last successful sync changed the
+// clock. It is false when the source reported it was not modified.
+func (tk *TimeKeeper) LastSyncUpdated() bool {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.lastSyncUpdated
+}
+
+// LastSyncRTT returns the round trip of the fetch of the last successful
+// sync, half of it was added to the remote time when it was applied
+func (tk *TimeKeeper) LastSyncRTT() time.Duration {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return tk.lastSyncRTT
+}
+
+// SyncAge returns the real time elapsed since the last successful sync, or
+// zero if the TimeKeeper was never synchronized
+func (tk *TimeKeeper) SyncAge() time.Duration {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	if tk.lastSync.IsZero() {
+		return 0
+	}
+	return tk.realNow().Sub(tk.lastSync)
+}
+
+// Healthy reports whether the TimeKeeper was synchronized within maxAge. It
+// is false until the first successful sync.
+func (tk *TimeKeeper) Healthy(maxAge time.Duration) bool {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	return !tk.lastSync.IsZero() && tk.realNow().Sub(tk.lastSync) <= maxAge
+}
+
+// defaultAutoSyncInterval is used by StartAutoSync for non-positive
+// intervals
+const defaultAutoSyncInterval = time.Minute
+
+// StartAutoSync synchronizes from url every interval, or every minute for
+// non-positive intervals, until ctx is cancelled or the TimeKeeper is
+// closed. Failures do not stop the loop and are reported on the returned
+// channel, which is closed once the loop has exited. Errors are dropped if
+// the channel is not drained.
+func (tk *TimeKeeper) StartAutoSync(ctx context.Context, url string, interval time.Duration) <-chan error {
+	if interval <= 0 {
+		interval = defaultAutoSyncInterval
+	}
+	errs := make(chan error, 1)
+	closing := tk.doneChan()
+
+	go func() {
+		defer close(errs)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-closing:
+				return
+			case <-ticker.C:
+			}
+
+			if err := tk.SynchronizeContext(ctx, url); err != nil && ctx.Err() == nil {
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+		}
+	}()
+
+	return errs
+}
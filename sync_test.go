@@ -0,0 +1,438 @@
+package timewarp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStartAutoSyncNonPositiveInterval(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer tk.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := tk.StartAutoSync(ctx, "http://127.0.0.1:1/", 0)
+	cancel()
+	select {
+	case <-errs:
+	case <-time.After(time.Second):
+		t.Fatal("auto sync loop did not exit after cancel")
+	}
+}
+
+func TestSynchronizeRejectsInvalidRemoteMultiplier(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"simulated_time":"2030-01-01T00:00:00Z","multiplier":-1}`))
+	}))
+	defer srv.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(WithStartTime(start), WithPositiveMultiplier())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	err = tk.Synchronize(srv.URL)
+	if !errors.Is(err, ErrInvalidMultiplier) || !errors.Is(err, ErrSyncFailed) {
+		t.Fatalf("Synchronize with a negative remote multiplier = %v, want ErrInvalidMultiplier and ErrSyncFailed", err)
+	}
+	if m := tk.Multiplier(); m != 1 {
+		t.Errorf("Multiplier() = %v, want 1", m)
+	}
+	if now := tk.Now(); now.Year() != 2024 {
+		t.Errorf("Now() = %v, the rejected sync moved the clock", now)
+	}
+}
+
+func TestSynchronizeContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := tk.SynchronizeContext(ctx, srv.URL); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SynchronizeContext past the deadline = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// headerTransport adds a header to every request
+type headerTransport struct {
+	key, value string
+}
+
+func (h headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(h.key, h.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSetHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"simulated_time":"2030-01-01T00:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tk.Synchronize(srv.URL); err == nil {
+		t.Fatal("Synchronize without the custom client succeeded")
+	}
+	tk.SetHTTPClient(&http.Client{Transport: headerTransport{"Authorization", "Bearer secret"}})
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatalf("Synchronize with the custom client: %v", err)
+	}
+	if now := tk.Now(); now.Year() != 2030 {
+		t.Errorf("Now() = %v after sync, want 2030", now)
+	}
+}
+
+// serveJSON starts a server answering every request with body
+func serveJSON(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSyncConfigField(t *testing.T) {
+	srv := serveJSON(t, `{"data":{"now":"01/02/2030 10:00"}}`)
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tk.Synchronize(srv.URL); err == nil {
+		t.Error("Synchronize with the default field succeeded")
+	}
+
+	tk.SetSyncConfig(SyncConfig{Field: "data.now", Layout: "01/02/2006 15:04"})
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2030, 1, 2, 10, 0, 0, 0, time.UTC)
+	if d := tk.Now().Sub(want); d < 0 || d > time.Second {
+		t.Errorf("Now() = %v after sync, want about %v", tk.Now(), want)
+	}
+}
+
+func TestSynchronizeMultiplier(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want float64
+	}{
+		{"present", `{"simulated_time":"2030-01-01T00:00:00Z","multiplier":60}`, 60},
+		{"absent", `{"simulated_time":"2030-01-01T00:00:00Z"}`, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := serveJSON(t, tt.body)
+			tk, err := New("2024-01-01", "00:00", "UTC", 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tk.Synchronize(srv.URL); err != nil {
+				t.Fatal(err)
+			}
+			if got := tk.Multiplier(); got != tt.want {
+				t.Errorf("Multiplier() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartAutoSync(t *testing.T) {
+	srv := serveJSON(t, `{"simulated_time":"2030-01-01T00:00:00Z"}`)
+	before := runtime.NumGoroutine()
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// idle connections would outlive the loop
+	tk.SetHTTPClient(&http.Client{Transport: &http.Transport{DisableKeepAlives: true}})
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := tk.StartAutoSync(ctx, srv.URL, 10*time.Millisecond)
+
+	deadline := time.Now().Add(time.Second)
+	for tk.LastSync().IsZero() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if now := tk.Now(); now.Year() != 2030 {
+		t.Errorf("Now() = %v, the auto sync did not apply", now)
+	}
+
+	cancel()
+	for err := range errs {
+		t.Errorf("auto sync failed: %v", err)
+	}
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines left after cancelling the auto sync, %d before", n, before)
+	}
+}
+
+// fakeSource is a TimeSource returning fixed values
+type fakeSource struct {
+	simTime    time.Time
+	multiplier float64
+	err        error
+}
+
+func (s fakeSource) Fetch(ctx context.Context) (time.Time, float64, error) {
+	return s.simTime, s.multiplier, s.err
+}
+
+func TestSynchronizeSlew(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(WithStartTime(start), WithNowFunc(func() time.Time { return real }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.SetSyncConfig(SyncConfig{Slew: time.Second})
+
+	if err := tk.SynchronizeFrom(context.Background(), fakeSource{simTime: start.Add(10 * time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	const tolerance = time.Millisecond
+	synced := real
+	for _, step := range []struct {
+		real time.Duration
+		want time.Duration
+	}{
+		{0, 0},
+		{500 * time.Millisecond, 500*time.Millisecond + 5*time.Second},
+		{time.Second, time.Second + 10*time.Second},
+		{2 * time.Second, 2*time.Second + 10*time.Second},
+	} {
+		real = synced.Add(step.real)
+		if d := tk.Now().Sub(start.Add(step.want)); d < -tolerance || d > tolerance {
+			t.Errorf("%v into the slew Now() is %v off", step.real, d)
+		}
+	}
+}
+
+func TestSlewClampedOnSlowdown(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(WithStartTime(start), WithNowFunc(func() time.Time { return real }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tk.SetSyncConfig(SyncConfig{Slew: time.Second})
+
+	// an hour behind is corrected at the limit of half the multiplier
+	if err := tk.SynchronizeFrom(context.Background(), fakeSource{simTime: start.Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := tk.EffectiveRate(); got != 0.5 {
+		t.Errorf("EffectiveRate() while slewing = %v, want 0.5", got)
+	}
+
+	tk.SetMultiplier(0.1)
+	if got := tk.EffectiveRate(); got != 0.05 {
+		t.Errorf("EffectiveRate() after slowing down = %v, want 0.05", got)
+	}
+	before := tk.Now()
+	real = real.Add(time.Second)
+	if got := tk.Now().Sub(before); got != 50*time.Millisecond {
+		t.Errorf("Now() advanced %v in a second, want 50ms", got)
+	}
+
+	tk.SetMultiplier(0)
+	if got := tk.EffectiveRate(); got != 0 {
+		t.Errorf("EffectiveRate() frozen = %v, want 0", got)
+	}
+}
+
+func TestSynchronizeAny(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	working := serveJSON(t, `{"simulated_time":"2030-01-01T00:00:00Z"}`)
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	success, failure := &fakeMetric{}, &fakeMetric{}
+	tk.SetMetrics(Metrics{SyncSuccess: success, SyncFailure: failure})
+
+	if err := tk.SynchronizeAny(context.Background(), failing.URL, working.URL); err != nil {
+		t.Fatalf("SynchronizeAny with one working url: %v", err)
+	}
+	if now := tk.Now(); now.Year() != 2030 {
+		t.Errorf("Now() = %v, want the time of the working server", now)
+	}
+	if _, n := failure.get(); n != 1 {
+		t.Errorf("SyncFailure = %d, want 1", n)
+	}
+	if _, n := success.get(); n != 1 {
+		t.Errorf("SyncSuccess = %d, want 1", n)
+	}
+
+	err = tk.SynchronizeAny(context.Background(), failing.URL, failing.URL)
+	if !errors.Is(err, ErrSyncFailed) || !strings.Contains(err.Error(), failing.URL) {
+		t.Errorf("SynchronizeAny with failing urls = %v", err)
+	}
+}
+
+func TestHealthy(t *testing.T) {
+	srv := serveJSON(t, `{"simulated_time":"2030-01-01T00:00:00Z"}`)
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tk.Healthy(time.Hour) {
+		t.Error("Healthy() before any sync = true")
+	}
+	if age := tk.SyncAge(); age != 0 {
+		t.Errorf("SyncAge() before any sync = %v, want 0", age)
+	}
+
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if !tk.Healthy(time.Hour) {
+		t.Error("Healthy() after a sync = false")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if tk.Healthy(time.Millisecond) {
+		t.Error("Healthy() past maxAge = true")
+	}
+}
+
+func TestSynchronizeRTT(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Write([]byte(`{"simulated_time":"2030-01-01T00:00:00Z","multiplier":1}`))
+	}))
+	defer srv.Close()
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	rtt := tk.LastSyncRTT()
+	if rtt < delay || rtt > 10*delay {
+		t.Fatalf("LastSyncRTT() = %v, want a little over %v", rtt, delay)
+	}
+	tk.Pause()
+	// the remote time is assumed to be read half way through the round trip
+	remote := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if d := tk.Now().Sub(remote); d < rtt/2 || d > rtt/2+10*time.Millisecond {
+		t.Errorf("Now() is %v past the remote time, want about %v", d, rtt/2)
+	}
+}
+
+func TestSynchronizeETag(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"simulated_time":"2030-01-01T00:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	tk, err := New("2024-01-01", "00:00", "UTC", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if !tk.LastSyncUpdated() {
+		t.Error("LastSyncUpdated() after the first sync = false")
+	}
+	anchor := tk.StartRealTime()
+
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if tk.LastSyncUpdated() {
+		t.Error("LastSyncUpdated() after a 304 = true")
+	}
+	if got := tk.StartRealTime(); !got.Equal(anchor) {
+		t.Errorf("a 304 re-anchored the clock from %v to %v", anchor, got)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("server saw %d requests, want 2", n)
+	}
+}
+
+func TestSynchronizeURLValidation(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"simulated_time":"2030-01-01T00:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tk.SetHTTPClient(srv.Client())
+
+	for _, url := range []string{
+		"file:///etc/passwd",
+		"example.com/time",
+		"localhost:8080/time",
+		"ftp://example.com/time",
+		"https:///time",
+	} {
+		if err := tk.Synchronize(url); !errors.Is(err, ErrInvalidSyncURL) {
+			t.Errorf("Synchronize(%q) error = %v, want ErrInvalidSyncURL", url, err)
+		}
+	}
+
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatalf("Synchronize(%q) = %v", srv.URL, err)
+	}
+	if got := tk.Now().Year(); got != 2030 {
+		t.Errorf("Now() after the https sync is in %d, want 2030", got)
+	}
+
+	tk.SetSyncAllowedHosts("time.example.com")
+	if err := tk.Synchronize(srv.URL); !errors.Is(err, ErrInvalidSyncURL) {
+		t.Errorf("Synchronize to a host not allowed error = %v, want ErrInvalidSyncURL", err)
+	}
+	tk.SetSyncAllowedHosts("127.0.0.1")
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Errorf("Synchronize to an allowed host = %v", err)
+	}
+}
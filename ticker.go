@@ -0,0 +1,132 @@
+package timewarp
+
+import "time"
+
+// TickPolicy decides what a SimTicker does with ticks it cannot deliver. A
+// tick is missed when the consumer has not drained the previous one, or
+// when a high multiplier or a jump skipped whole intervals.
+type TickPolicy int
+
+const (
+	// DropMissed drops missed ticks like time.Ticker, it is the default
+	DropMissed TickPolicy = iota
+	// CountMissed drops missed ticks but counts them for Missed
+	CountMissed
+)
+
+// TickerOption configures NewTicker
+type TickerOption func(*tickerConfig)
+
+// tickerConfig holds the settings collected from ticker options
+type tickerConfig struct {
+	policy TickPolicy
+}
+
+// WithTickPolicy sets what the ticker does with missed ticks
+func WithTickPolicy(policy TickPolicy) TickerOption {
+	return func(c *tickerConfig) {
+		c.policy = policy
+	}
+}
+
+// SimTicker delivers ticks at a fixed simulated interval. The real interval
+// follows the multiplier so the simulated cadence stays constant.
+type SimTicker struct {
+	C <-chan time.Time
+
+	c       chan time.Time
+	d       time.Duration
+	timer   *SimTimer
+	stopped bool
+	policy  TickPolicy
+	missed  int
+}
+
+// NewTicker returns a ticker sending the simulated time on its channel
+// every d of simulated time. Like time.Ticker it never blocks on a slow
+// consumer. Missed ticks are handled according to the TickPolicy. It panics
+// if d is not positive.
+func (tk *TimeKeeper) NewTicker(d time.Duration, opts ...TickerOption) *SimTicker {
+	if d <= 0 {
+		panic("timewarp: non-positive interval for NewTicker")
+	}
+
+	var config tickerConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	c := make(chan time.Time, 1)
+	t := &SimTicker{
+		C:      c,
+		c:      c,
+		d:      d,
+		policy: config.policy,
+	}
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	t.timer = &SimTimer{
+		tk:   tk,
+		when: tk.now().Add(d),
+		f:    t.tick,
+	}
+	tk.startTimer(t.timer)
+	return t
+}
+
+// Stop turns off the ticker. It is safe to call more than once and does not
+// close the channel.
+func (t *SimTicker) Stop() {
+	tk := t.timer.tk
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	t.stopped = true
+	tk.stopTimer(t.timer)
+}
+
+// Missed returns the number of ticks missed since the previous call and
+// resets it, ticks are only counted with the CountMissed policy
+func (t *SimTicker) Missed() int {
+	tk := t.timer.tk
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	missed := t.missed
+	t.missed = 0
+	return missed
+}
+
+// tick delivers a tick and schedules the next one, skipping any intervals
+// that were missed entirely
+func (t *SimTicker) tick(now time.Time) {
+	delivered := true
+	select {
+	case t.c <- now:
+	default:
+		delivered = false
+	}
+
+	tk := t.timer.tk
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+	if t.stopped {
+		return
+	}
+
+	var missed time.Duration
+	next := t.timer.when.Add(t.d)
+	if !next.After(now) {
+		missed = now.Sub(t.timer.when) / t.d
+		next = t.timer.when.Add((missed + 1) * t.d)
+	}
+	if t.policy == CountMissed {
+		t.missed += int(missed)
+		if !delivered {
+			t.missed++
+		}
+	}
+	t.timer.when = next
+	tk.startTimer(t.timer)
+}
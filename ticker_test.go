@@ -0,0 +1,131 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTickerKeepsSimulatedCadence(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 6000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	ticker := tk.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	var ticks []time.Time
+	for i := 0; i < 6; i++ {
+		if i == 3 {
+			tk.SetMultiplier(3000)
+		}
+		select {
+		case tick := <-ticker.C:
+			ticks = append(ticks, tick)
+		case <-time.After(time.Second):
+			t.Fatalf("tick %d did not arrive", i)
+		}
+	}
+	for i := 1; i < len(ticks); i++ {
+		// ticks are delivered a little after their deadline
+		if gap := ticks[i].Sub(ticks[i-1]); gap < 30*time.Second || gap > 90*time.Second {
+			t.Errorf("ticks %d and %d are %v apart, want about a minute", i-1, i, gap)
+		}
+	}
+}
+
+func TestTickerStop(t *testing.T) {
+	tk := NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ticker := tk.NewTicker(time.Minute)
+
+	tk.Advance(time.Minute)
+	select {
+	case tick := <-ticker.C:
+		if want := time.Date(2024, 1, 1, 0, 1, 0, 0, time.UTC); !tick.Equal(want) {
+			t.Errorf("tick = %v, want %v", tick, want)
+		}
+	default:
+		t.Fatal("no tick after advancing one interval")
+	}
+
+	ticker.Stop()
+	ticker.Stop()
+	tk.Advance(time.Hour)
+	select {
+	case tick := <-ticker.C:
+		t.Errorf("tick %v after Stop", tick)
+	default:
+	}
+}
+
+func TestTickerCountMissed(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+	counting := tk.NewTicker(time.Second, WithTickPolicy(CountMissed))
+	defer counting.Stop()
+	dropping := tk.NewTicker(time.Second)
+	defer dropping.Stop()
+
+	// a jump over ten intervals delivers one tick and misses nine
+	tk.Advance(10 * time.Second)
+
+	for name, ticker := range map[string]*SimTicker{"CountMissed": counting, "DropMissed": dropping} {
+		select {
+		case tick := <-ticker.C:
+			if want := start.Add(10 * time.Second); !tick.Equal(want) {
+				t.Errorf("%s: first tick = %v, want %v", name, tick, want)
+			}
+		default:
+			t.Fatalf("%s: no tick was delivered", name)
+		}
+	}
+	if got := counting.Missed(); got != 9 {
+		t.Errorf("CountMissed: Missed() = %d, want 9", got)
+	}
+	if got := counting.Missed(); got != 0 {
+		t.Errorf("CountMissed: Missed() after reading it = %d, want 0", got)
+	}
+	if got := dropping.Missed(); got != 0 {
+		t.Errorf("DropMissed: Missed() = %d, want 0", got)
+	}
+
+	tk.Advance(time.Second)
+	select {
+	case tick := <-counting.C:
+		if want := start.Add(11 * time.Second); !tick.Equal(want) {
+			t.Errorf("tick after draining = %v, want %v", tick, want)
+		}
+	default:
+		t.Error("no tick after draining the channel")
+	}
+}
+
+func TestTickerSlowConsumer(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	// a simulated second lasts 10µs of real time
+	ticker := tk.NewTicker(time.Second, WithTickPolicy(CountMissed))
+	defer ticker.Stop()
+
+	var previous time.Time
+	for i := 0; i < 5; i++ {
+		time.Sleep(10 * time.Millisecond)
+		select {
+		case tick := <-ticker.C:
+			if !tick.After(previous) {
+				t.Errorf("tick %v is not after %v", tick, previous)
+			}
+			previous = tick
+		case <-time.After(time.Second):
+			t.Fatalf("tick %d did not arrive", i)
+		}
+	}
+	if got := ticker.Missed(); got == 0 {
+		t.Error("Missed() = 0 after a slow consumer at 100000x")
+	}
+}
@@ -0,0 +1,183 @@
+package timewarp
+
+import "time"
+
+// defaultTimelineSize is the number of segments kept by Timeline unless
+// changed with SetTimelineSize
+const defaultTimelineSize = 256
+
+// Segment is a stretch of real time over which the simulated clock runs at
+// a constant rate
+type Segment struct {
+	// RealStart is the real instant the segment starts at
+	RealStart time.Time
+	// RealEnd is the real instant the next segment starts at. It is the
+	// zero time for the last segment which is still running.
+	RealEnd time.Time
+	// SimStart is the simulated time at RealStart
+	SimStart time.Time
+	// Rate is the simulated time elapsed per unit of real time at
+	// RealStart. It is the multiplier plus any sync correction being slewed
+	// in, and zero while paused or in manual mode.
+	Rate float64
+	// Acceleration is the change of Rate per real second. It is only
+	// non-zero for a segment covering a RampMultiplier.
+	Acceleration float64
+	// Multiplier is the multiplier configured at RealStart
+	Multiplier float64
+	// Paused reports whether the clock was paused
+	Paused bool
+
+	// ramp is the generation of the ramp that recorded the segment
+	ramp uint64
+}
+
+// SimAt returns the simulated time at a real instant within the segment
+func (s Segment) SimAt(realTime time.Time) time.Time {
+	dt := float64(realTime.Sub(s.RealStart))
+	return s.SimStart.Add(time.Duration(dt*s.Rate + s.Acceleration*dt*dt/(2*float64(time.Second))))
+}
+
+// fit sets the acceleration of a segment so it reaches simEnd at realEnd.
+// The steps of a ramp are recorded as one segment this way.
+func (s *Segment) fit(realEnd, simEnd time.Time) {
+	dt := float64(realEnd.Sub(s.RealStart))
+	if dt <= 0 {
+		return
+	}
+	s.Acceleration = 2 * (float64(simEnd.Sub(s.SimStart)) - s.Rate*dt) * float64(time.Second) / (dt * dt)
+}
+
+// Timeline returns the segments the simulated clock went through, oldest
+// first. Every speed change, jump, sync, pause, resume or reset starts a
+// new one, so Now at any past real instant can be rebuilt from the segment
+// covering it. The steps of a RampMultiplier are folded into one
+// accelerating segment. Now is rebuilt before quantization, loops, jitter,
+// bounds and leap seconds are applied. At most the number of segments set
+// with SetTimelineSize are kept.
+func (tk *TimeKeeper) Timeline() []Segment {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	timeline := make([]Segment, len(tk.timeline))
+	copy(timeline, tk.timeline)
+	return timeline
+}
+
+// SetTimelineSize changes how many segments Timeline keeps. The most recent
+// ones are retained when shrinking and zero or below disables the timeline.
+func (tk *TimeKeeper) SetTimelineSize(n int) {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	tk.timelineSize = max(n, 0)
+	tk.trimTimeline()
+}
+
+// recordTimeline adds the segments described by the current anchors.
+// Segments planned from earlier anchors that start at or after them are
+// replaced. A segment merely continuing the previous one is not added, and
+// the steps of a ramp extend the segment of its first step. The caller must
+// hold the mutex.
+func (tk *TimeKeeper) recordTimeline() {
+	if tk.timelineSize == 0 {
+		return
+	}
+
+	segments := tk.segments()
+	i := len(tk.timeline)
+	for i > 0 && !tk.timeline[i-1].RealStart.Before(segments[0].RealStart) {
+		i--
+	}
+	timeline := tk.timeline[:i]
+	if gen := tk.timelineRamp; gen != 0 {
+		for j := range segments {
+			segments[j].ramp = gen
+		}
+		if i >= 2 && timeline[i-1].ramp == gen && timeline[i-2].ramp == gen {
+			timeline[i-2].fit(segments[0].RealStart, segments[0].SimStart)
+			i--
+			timeline = timeline[:i]
+		}
+	}
+	if i > 0 {
+		prev := timeline[i-1]
+		if next := segments[0]; prev.Rate == next.Rate && prev.Acceleration == 0 &&
+			prev.Multiplier == next.Multiplier && prev.Paused == next.Paused &&
+			prev.SimAt(next.RealStart).Equal(next.SimStart) {
+			segments = segments[1:]
+		}
+	}
+	timeline = append(timeline, segments...)
+	for j := max(i-1, 0); j < len(timeline)-1; j++ {
+		timeline[j].RealEnd = timeline[j+1].RealStart
+	}
+	timeline[len(timeline)-1].RealEnd = time.Time{}
+	tk.timeline = timeline
+	tk.trimTimeline()
+}
+
+// segments returns the segment starting at the current anchors, followed by
+// the one taking over once a slewed correction is complete. The caller must
+// hold the mutex.
+func (tk *TimeKeeper) segments() []Segment {
+	switch {
+	case tk.paused:
+		return []Segment{{
+			RealStart:  tk.pausedRealTime,
+			SimStart:   tk.pausedAt,
+			Multiplier: tk.multiplier,
+			Paused:     true,
+		}}
+	case tk.manual:
+		return []Segment{{
+			RealStart:  tk.startRealTime,
+			SimStart:   tk.startSimTime,
+			Multiplier: tk.multiplier,
+		}}
+	}
+
+	current := Segment{
+		RealStart:  tk.startRealTime,
+		SimStart:   tk.startSimTime,
+		Rate:       tk.multiplier,
+		Multiplier: tk.multiplier,
+	}
+	if tk.slewRate == 0 || !tk.slewUntil.After(tk.startRealTime) {
+		return []Segment{current}
+	}
+	current.Rate += tk.slewRate
+	return []Segment{current, {
+		RealStart:  tk.slewUntil,
+		SimStart:   tk.simAt(tk.slewUntil),
+		Rate:       tk.multiplier,
+		Multiplier: tk.multiplier,
+	}}
+}
+
+// trimTimeline drops the oldest segments beyond the configured size. The
+// caller must hold the mutex.
+func (tk *TimeKeeper) trimTimeline() {
+	if excess := len(tk.timeline) - tk.timelineSize; excess > 0 {
+		tk.timeline = append(tk.timeline[:0:0], tk.timeline[excess:]...)
+	}
+}
+
+// NowAt returns what the simulated clock read, or will read, at a real
+// instant. Past instants are looked up in the Timeline, so speed changes
+// and jumps since then are accounted for. Later ones follow the current
+// anchors like RealToSim. Instants older than the oldest segment kept are
+// extrapolated from it. Without a timeline the current anchors are used.
+func (tk *TimeKeeper) NowAt(realInstant time.Time) time.Time {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	i := len(tk.timeline) - 1
+	for i > 0 && realInstant.Before(tk.timeline[i].RealStart) {
+		i--
+	}
+	if i < 0 || i == len(tk.timeline)-1 && !realInstant.Before(tk.timeline[i].RealStart) {
+		return tk.simAt(realInstant)
+	}
+	return tk.timeline[i].SimAt(realInstant).In(tk.location)
+}
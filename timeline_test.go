@@ -0,0 +1,118 @@
+package timewarp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimelineRampIsOneSegment(t *testing.T) {
+	tk, err := NewWithOptions(WithStartTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+	tk.SetTimelineSize(8)
+
+	before := time.Now()
+	wantBefore := tk.NowAt(before)
+
+	type sample struct {
+		real     time.Time
+		sim      time.Time
+		realNext time.Time
+	}
+	var samples []sample
+	tk.RampMultiplier(100, 300*time.Millisecond)
+	deadline := time.Now().Add(400 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		real := time.Now()
+		sim := tk.Now()
+		samples = append(samples, sample{real, sim, time.Now()})
+		time.Sleep(7 * time.Millisecond)
+	}
+
+	if n := len(tk.Timeline()); n > 4 {
+		t.Fatalf("ramp left %d segments in the timeline: %+v", n, tk.Timeline())
+	}
+	if got := tk.NowAt(before); !got.Equal(wantBefore) {
+		t.Errorf("NowAt before the ramp = %v, want %v", got, wantBefore)
+	}
+	// the fitted segment smooths over the 10ms steps of the ramp
+	const tolerance = 250 * time.Millisecond
+	for _, s := range samples {
+		lo, hi := tk.NowAt(s.real).Add(-tolerance), tk.NowAt(s.realNext).Add(tolerance)
+		if s.sim.Before(lo) || s.sim.After(hi) {
+			t.Errorf("Now() read %v, NowAt rebuilt [%v, %v]", s.sim, lo, hi)
+		}
+	}
+}
+
+func TestTimelineSegments(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := real
+	sim := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(sim),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	real = real.Add(10 * time.Second)
+	tk.SetMultiplier(60)
+	real = real.Add(10 * time.Second)
+	tk.SetMultiplier(2)
+	real = real.Add(10 * time.Second)
+
+	want := []Segment{
+		{RealStart: start, RealEnd: start.Add(10 * time.Second), SimStart: sim, Rate: 1, Multiplier: 1},
+		{RealStart: start.Add(10 * time.Second), RealEnd: start.Add(20 * time.Second), SimStart: sim.Add(10 * time.Second), Rate: 60, Multiplier: 60},
+		{RealStart: start.Add(20 * time.Second), SimStart: sim.Add(10*time.Second + 10*time.Minute), Rate: 2, Multiplier: 2},
+	}
+	got := tk.Timeline()
+	if len(got) != len(want) {
+		t.Fatalf("Timeline() has %d segments, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if !g.RealStart.Equal(w.RealStart) || !g.RealEnd.Equal(w.RealEnd) || !g.SimStart.Equal(w.SimStart) ||
+			g.Rate != w.Rate || g.Multiplier != w.Multiplier || g.Paused {
+			t.Errorf("segment %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestNowAtPriorSegment(t *testing.T) {
+	real := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := real
+	sim := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk, err := NewWithOptions(
+		WithStartTime(sim),
+		WithNowFunc(func() time.Time { return real }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	real = real.Add(10 * time.Second)
+	tk.SetMultiplier(60)
+	real = real.Add(10 * time.Second)
+	tk.SetTime(sim.Add(24 * time.Hour))
+	real = real.Add(10 * time.Second)
+
+	tests := []struct {
+		real time.Duration
+		want time.Time
+	}{
+		{5 * time.Second, sim.Add(5 * time.Second)},
+		{15 * time.Second, sim.Add(10*time.Second + 5*time.Minute)},
+		{25 * time.Second, sim.Add(24*time.Hour + 5*time.Minute)},
+		{40 * time.Second, sim.Add(24*time.Hour + 20*time.Minute)},
+	}
+	for _, tt := range tests {
+		if got := tk.NowAt(start.Add(tt.real)); !got.Equal(tt.want) {
+			t.Errorf("NowAt(start+%v) = %v, want %v", tt.real, got, tt.want)
+		}
+	}
+}
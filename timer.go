@@ -0,0 +1,263 @@
+package timewarp
+
+import (
+	"context"
+	"time"
+)
+
+// SimTimer is a single event scheduled on the simulated clock, timers
+// created by NewTimer deliver the simulated time on C
+type SimTimer struct {
+	C <-chan time.Time
+
+	tk     *TimeKeeper
+	when   time.Time
+	f      func(now time.Time)
+	timer  *time.Timer
+	active bool
+	seq    uint64
+}
+
+// AfterFunc waits for the given simulated duration to elapse and then calls
+// f in its own goroutine. The real delay is recomputed whenever the speed
+// or the anchors of the simulation change, so f runs at the intended
+// simulated instant.
+func (tk *TimeKeeper) AfterFunc(d time.Duration, f func()) *SimTimer {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	st := &SimTimer{
+		tk:   tk,
+		when: tk.now().Add(d),
+		f:    func(time.Time) { go f() },
+	}
+	tk.startTimer(st)
+	return st
+}
+
+// NewTimer returns a timer sending the simulated time on its channel once d
+// of simulated time has elapsed, like time.NewTimer
+func (tk *TimeKeeper) NewTimer(d time.Duration) *SimTimer {
+	c := make(chan time.Time, 1)
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	st := &SimTimer{
+		C:    c,
+		tk:   tk,
+		when: tk.now().Add(d),
+		f: func(now time.Time) {
+			select {
+			case c <- now:
+			default:
+			}
+		},
+	}
+	tk.startTimer(st)
+	return st
+}
+
+// Stop prevents the timer from firing. It returns false if the timer has
+// already fired or been stopped.
+func (st *SimTimer) Stop() bool {
+	st.tk.mutex.Lock()
+	defer st.tk.mutex.Unlock()
+	return st.tk.stopTimer(st)
+}
+
+// Reset changes the timer to fire after d of simulated time from now. The
+// real delay is computed from the current multiplier. It returns true if
+// the timer was pending. A value already sent on C is not drained.
+func (st *SimTimer) Reset(d time.Duration) bool {
+	tk := st.tk
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	active := tk.stopTimer(st)
+	st.when = tk.now().Add(d)
+	tk.startTimer(st)
+	return active
+}
+
+// startTimer registers and arms a timer. Nothing is armed once the
+// TimeKeeper is closed. In manual mode a timer that is already due fires
+// right away, unless Advance is running timers and picks it up itself. The
+// caller must hold the mutex.
+func (tk *TimeKeeper) startTimer(st *SimTimer) {
+	if tk.closed {
+		return
+	}
+	if tk.timers == nil {
+		tk.timers = make(map[*SimTimer]struct{})
+	}
+	tk.timers[st] = struct{}{}
+	tk.timerSeq++
+	st.seq = tk.timerSeq
+	st.active = true
+	tk.armTimer(st)
+	if tk.manual && tk.firing == 0 && !st.when.After(tk.now()) {
+		st.timer = time.AfterFunc(0, st.fire)
+	}
+}
+
+// stopTimer unregisters a timer, the caller must hold the mutex
+func (tk *TimeKeeper) stopTimer(st *SimTimer) bool {
+	if !st.active {
+		return false
+	}
+	if st.timer != nil {
+		st.timer.Stop()
+		st.timer = nil
+	}
+	st.active = false
+	delete(tk.timers, st)
+	return true
+}
+
+// armTimer schedules the real timer backing st from the current anchors.
+// Timers are left unarmed while the simulated clock is paused or not moving
+// towards their deadline. In manual mode they only run from Advance, apart
+// from those already due when started. The caller must hold the mutex.
+func (tk *TimeKeeper) armTimer(st *SimTimer) {
+	if st.timer != nil {
+		st.timer.Stop()
+		st.timer = nil
+	}
+
+	if tk.manual {
+		return
+	}
+
+	now := tk.realNow()
+	var delay time.Duration
+	if st.when.After(tk.simAt(now)) {
+		if tk.paused || tk.multiplier <= 0 {
+			return
+		}
+		realWhen := tk.realAt(st.when)
+		if tk.parent != nil {
+			realWhen, now = tk.parent.wallAt(realWhen), time.Now()
+			if realWhen.Before(now) {
+				// the parent clock never reaches the deadline
+				return
+			}
+		}
+		delay = realWhen.Sub(now) + 1
+	}
+	st.timer = time.AfterFunc(delay, st.fire)
+}
+
+// wallAt maps a simulated instant of tk to the wall-clock instant it is
+// reached at, following the chain of parents of derived TimeKeepers. It
+// returns the zero time if the instant is never reached. It only reads
+// published views, so no lock is needed.
+func (tk *TimeKeeper) wallAt(simTime time.Time) time.Time {
+	v := tk.published.Load()
+	if v == nil || v.paused || v.manual || v.multiplier <= 0 {
+		return time.Time{}
+	}
+
+	realTime := v.realAt(simTime)
+	if tk.parent != nil {
+		return tk.parent.wallAt(realTime)
+	}
+	return realTime
+}
+
+// changed publishes the view and reschedules the timers after the anchors
+// or the multiplier changed. The caller must hold the mutex.
+func (tk *TimeKeeper) changed() {
+	tk.publish()
+	tk.rearmTimers()
+}
+
+// rearmTimers reschedules all pending timers after the anchors or the
+// multiplier changed, including those of derived TimeKeepers which follow
+// this clock. The caller must hold the mutex.
+func (tk *TimeKeeper) rearmTimers() {
+	for st := range tk.timers {
+		tk.armTimer(st)
+	}
+	for _, child := range tk.children {
+		child.mutex.Lock()
+		child.rearmTimers()
+		child.mutex.Unlock()
+	}
+}
+
+// fire runs when the real timer expires. It calls f if the deadline was
+// reached or rearms the timer otherwise.
+func (st *SimTimer) fire() {
+	tk := st.tk
+	tk.mutex.Lock()
+	if !st.active {
+		tk.mutex.Unlock()
+		return
+	}
+	now := tk.now()
+	if now.Before(st.when) {
+		tk.armTimer(st)
+		tk.mutex.Unlock()
+		return
+	}
+	tk.stopTimer(st)
+	tk.mutex.Unlock()
+
+	st.f(now)
+}
+
+// At returns a channel that receives the simulated time once the simulated
+// clock reaches t. It fires right away if t is already in the simulated
+// past. Pending channels can be cancelled with StopAt.
+func (tk *TimeKeeper) At(t time.Time) <-chan time.Time {
+	c := make(chan time.Time, 1)
+
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	st := &SimTimer{tk: tk, when: t}
+	st.f = func(now time.Time) {
+		tk.mutex.Lock()
+		delete(tk.pendingAt, c)
+		tk.mutex.Unlock()
+		c <- now
+	}
+	if tk.pendingAt == nil {
+		tk.pendingAt = make(map[<-chan time.Time]*SimTimer)
+	}
+	tk.pendingAt[c] = st
+	tk.startTimer(st)
+	return c
+}
+
+// StopAt cancels a channel returned by At. It returns false if the channel
+// has already fired or been cancelled.
+func (tk *TimeKeeper) StopAt(c <-chan time.Time) bool {
+	tk.mutex.Lock()
+	defer tk.mutex.Unlock()
+
+	st, ok := tk.pendingAt[c]
+	if !ok {
+		return false
+	}
+	delete(tk.pendingAt, c)
+	return tk.stopTimer(st)
+}
+
+// WaitUntil blocks until the simulated clock reaches t, following speed
+// changes while waiting. It returns right away if t is already in the
+// simulated past. It returns ctx.Err() if ctx is done first or ErrClosed if
+// the TimeKeeper is closed.
+func (tk *TimeKeeper) WaitUntil(ctx context.Context, t time.Time) error {
+	c := tk.At(t)
+	select {
+	case <-c:
+		return nil
+	case <-ctx.Done():
+		tk.StopAt(c)
+		return ctx.Err()
+	case <-tk.doneChan():
+		return ErrClosed
+	}
+}
@@ -0,0 +1,179 @@
+package timewarp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAfterFuncFollowsSpeedChange(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 6000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	start := time.Now()
+	fired := make(chan time.Time, 1)
+	tk.AfterFunc(10*time.Minute, func() { fired <- time.Now() })
+	time.Sleep(20 * time.Millisecond)
+	tk.SetMultiplier(12000)
+
+	select {
+	case at := <-fired:
+		// 20ms at 6000x then the remaining 8 minutes at 12000x
+		if real := at.Sub(start); real < 50*time.Millisecond || real > 90*time.Millisecond {
+			t.Errorf("AfterFunc fired after %v, want about 60ms", real)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("AfterFunc did not fire")
+	}
+}
+
+func TestAtFiresPromptly(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	target := tk.Now().Add(time.Minute)
+	start := time.Now()
+	select {
+	case at := <-tk.At(target):
+		if at.Before(target) {
+			t.Errorf("At fired at %v, before %v", at, target)
+		}
+		if real := time.Since(start); real > 100*time.Millisecond {
+			t.Errorf("At one simulated minute away at 3600x took %v", real)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("At did not fire")
+	}
+
+	c := tk.At(target.Add(time.Hour))
+	if !tk.StopAt(c) {
+		t.Error("StopAt on a pending channel = false")
+	}
+	if tk.StopAt(c) {
+		t.Error("second StopAt = true")
+	}
+}
+
+func TestWaitUntil(t *testing.T) {
+	tk, err := New("2024-01-01", "00:00", "UTC", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tk.Close()
+
+	// five simulated seconds at 100x are 50ms of real time
+	target := tk.Now().Add(5 * time.Second)
+	start := time.Now()
+	if err := tk.WaitUntil(context.Background(), target); err != nil {
+		t.Fatal(err)
+	}
+	if real := time.Since(start); real < 40*time.Millisecond || real > 150*time.Millisecond {
+		t.Errorf("WaitUntil took %v, want about 50ms", real)
+	}
+	if tk.Now().Before(target) {
+		t.Errorf("WaitUntil returned at %v, before %v", tk.Now(), target)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := tk.WaitUntil(ctx, tk.Now().Add(time.Hour)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitUntil past the context deadline = %v", err)
+	}
+}
+
+func TestTimerResetAndStop(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+
+	timer := tk.NewTimer(time.Hour)
+	tk.Advance(30 * time.Minute)
+	if !timer.Reset(time.Hour) {
+		t.Error("Reset of a pending timer = false")
+	}
+	tk.Advance(45 * time.Minute)
+	select {
+	case at := <-timer.C:
+		t.Fatalf("timer fired at %v, the Reset did not extend it", at)
+	default:
+	}
+	tk.Advance(15 * time.Minute)
+	select {
+	case at := <-timer.C:
+		if want := start.Add(90 * time.Minute); !at.Equal(want) {
+			t.Errorf("timer fired at %v, want %v", at, want)
+		}
+	default:
+		t.Fatal("the reset timer did not fire")
+	}
+
+	timer = tk.NewTimer(time.Minute)
+	if !timer.Stop() {
+		t.Error("Stop of a pending timer = false")
+	}
+	if timer.Stop() {
+		t.Error("second Stop = true")
+	}
+	tk.Advance(time.Hour)
+	select {
+	case at := <-timer.C:
+		t.Errorf("stopped timer fired at %v", at)
+	default:
+	}
+}
+
+func TestAtPastManual(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+
+	for _, at := range []time.Time{start.Add(-time.Hour), start} {
+		select {
+		case got := <-tk.At(at):
+			if !got.Equal(start) {
+				t.Errorf("At(%v) = %v, want %v", at, got, start)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("At(%v) in the past did not fire without Advance", at)
+		}
+	}
+}
+
+func TestWaitUntilPastManual(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tk.WaitUntil(ctx, start.Add(-time.Minute)); err != nil {
+		t.Errorf("WaitUntil in the past = %v, want nil", err)
+	}
+}
+
+func TestTimerZeroManual(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := NewManual(start)
+
+	timer := tk.NewTimer(0)
+	select {
+	case at := <-timer.C:
+		if !at.Equal(start) {
+			t.Errorf("NewTimer(0) fired at %v, want %v", at, start)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("NewTimer(0) did not fire without Advance")
+	}
+
+	timer = tk.NewTimer(time.Hour)
+	timer.Reset(-time.Minute)
+	select {
+	case <-timer.C:
+	case <-time.After(time.Second):
+		t.Fatal("Reset(-1m) did not fire without Advance")
+	}
+}
@@ -0,0 +1,131 @@
+package timewarptest
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kaneetz/timewarp"
+)
+
+// Firing records a callback run by a Clock
+type Firing struct {
+	// Name is the name the callback was registered or recorded with
+	Name string
+	// At is the simulated time the callback ran at
+	At time.Time
+}
+
+// Clock is a manual-mode TimeKeeper for tests of scheduled logic. Forward
+// moves it from deadline to deadline, so every due timer, ticker, OnPeriod
+// and OnSchedule callback runs synchronously at its own simulated instant.
+// The callbacks registered through the Clock or calling Record are logged
+// in the order they ran. AfterFunc callbacks still run in their own
+// goroutine, so their order is not deterministic. Neither is the order of
+// Scheduler jobs, which run on the goroutine calling Run. Forward does not
+// wait for them, so they may observe a later Now than they were due at.
+type Clock struct {
+	*timewarp.TimeKeeper
+
+	t     testing.TB
+	mutex sync.Mutex
+	fired []Firing
+}
+
+// NewClock returns a Clock starting at start. It is closed when the test
+// finishes.
+func NewClock(t testing.TB, start time.Time) *Clock {
+	t.Helper()
+
+	c := &Clock{TimeKeeper: timewarp.NewManual(start), t: t}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// After registers f to run once d of simulated time from now, logged as
+// name. A non-positive d runs it right away, or within the running Forward
+// when called from a callback. The returned function cancels it.
+// Callbacks due at the same instant run in the order they were registered.
+func (c *Clock) After(d time.Duration, name string, f func()) (stop func()) {
+	return c.Schedule(&once{at: c.Now().Add(d)}, name, f)
+}
+
+// Schedule registers f to run at every occurrence of schedule, logged as
+// name. The returned function stops further calls.
+func (c *Clock) Schedule(schedule timewarp.Schedule, name string, f func()) (stop func()) {
+	return c.OnSchedule(schedule, func(occurrence time.Time) {
+		c.record(name, occurrence)
+		if f != nil {
+			f()
+		}
+	})
+}
+
+// Record logs a firing of name at the current simulated time. It lets
+// callbacks registered directly on the TimeKeeper take part in the log.
+func (c *Clock) Record(name string) {
+	c.record(name, c.Now())
+}
+
+// record appends a firing to the log
+func (c *Clock) record(name string, at time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.fired = append(c.fired, Firing{Name: name, At: at})
+}
+
+// Forward advances the clock by d, stopping at every pending deadline on
+// the way so callbacks observe Now at the instant they were due. Callbacks
+// scheduled by other callbacks within d run as well. It returns once every
+// callback due by the end has run.
+func (c *Clock) Forward(d time.Duration) {
+	c.t.Helper()
+
+	end := c.Now().Add(d)
+	for {
+		next, ok := c.NextDeadline()
+		if !ok || next.After(end) {
+			break
+		}
+		c.Advance(max(next.Sub(c.Now()), 0))
+	}
+	c.Advance(max(end.Sub(c.Now()), 0))
+}
+
+// Fired returns the firings logged so far, oldest first
+func (c *Clock) Fired() []Firing {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return slices.Clone(c.fired)
+}
+
+// AssertOrder fails the test unless the names of the firings logged so far
+// are exactly names, in order
+func (c *Clock) AssertOrder(names ...string) {
+	c.t.Helper()
+
+	fired := c.Fired()
+	got := make([]string, len(fired))
+	for i, f := range fired {
+		got[i] = f.Name
+	}
+	if !slices.Equal(got, names) {
+		c.t.Errorf("timewarptest: callbacks fired in order %q, want %q", got, names)
+	}
+}
+
+// once is a schedule with a single occurrence, which may already be due
+type once struct {
+	at   time.Time
+	used bool
+}
+
+// Next returns the occurrence on the first call and the zero time after
+func (s *once) Next(time.Time) time.Time {
+	if s.used {
+		return time.Time{}
+	}
+	s.used = true
+	return s.at
+}
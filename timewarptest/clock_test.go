@@ -0,0 +1,71 @@
+package timewarptest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kaneetz/timewarp"
+	"github.com/kaneetz/timewarp/timewarptest"
+)
+
+func TestClockFiresInOrder(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := timewarptest.NewClock(t, start)
+
+	// registered out of order, fired by deadline
+	clock.After(3*time.Minute, "report", nil)
+	clock.After(time.Minute, "open", func() {
+		if got := clock.Now(); !got.Equal(start.Add(time.Minute)) {
+			t.Errorf("open ran at %v, want %v", got, start.Add(time.Minute))
+		}
+	})
+	clock.Schedule(timewarp.Every(2*time.Minute), "poll", nil)
+
+	clock.Forward(3 * time.Minute)
+	clock.AssertOrder("open", "poll", "report")
+
+	fired := clock.Fired()
+	for i, want := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		if !fired[i].At.Equal(start.Add(want)) {
+			t.Errorf("%s fired at %v, want %v", fired[i].Name, fired[i].At, start.Add(want))
+		}
+	}
+}
+
+func TestClockRecordAndNested(t *testing.T) {
+	clock := timewarptest.NewClock(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	clock.OnSchedule(timewarp.Every(time.Hour), func(time.Time) { clock.Record("hourly") })
+	clock.After(90*time.Minute, "first", func() {
+		clock.After(0, "nested", nil)
+	})
+
+	clock.Forward(2 * time.Hour)
+	clock.AssertOrder("hourly", "first", "nested", "hourly")
+}
+
+func TestClockScheduler(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := timewarptest.NewClock(t, start)
+
+	occurrences := make(chan time.Time, 3)
+	s := timewarp.NewScheduler(clock.TimeKeeper)
+	s.Add(timewarp.Every(time.Minute), func(at time.Time) { occurrences <- at })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	// the jobs run on the Run goroutine, possibly after Forward returned
+	clock.Forward(3 * time.Minute)
+	for i := 1; i <= 3; i++ {
+		select {
+		case at := <-occurrences:
+			if want := start.Add(time.Duration(i) * time.Minute); !at.Equal(want) {
+				t.Errorf("occurrence %d = %v, want %v", i, at, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("occurrence %d did not run", i)
+		}
+	}
+}
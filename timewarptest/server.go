@@ -0,0 +1,60 @@
+// Package timewarptest provides helpers for testing code that synchronizes
+// a timewarp.TimeKeeper or schedules work on its clock
+package timewarptest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Server is a fake time API serving a fixed simulated time and multiplier
+// in the format read by TimeKeeper.Synchronize. The values only change
+// through Set, so every sync against it is deterministic.
+type Server struct {
+	*httptest.Server
+
+	mutex      sync.Mutex
+	simTime    time.Time
+	multiplier float64
+}
+
+// NewServer starts a Server serving sim and multiplier. It is closed when
+// the test finishes.
+func NewServer(t testing.TB, sim time.Time, multiplier float64) *Server {
+	t.Helper()
+
+	s := &Server{simTime: sim, multiplier: multiplier}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Set changes the simulated time and multiplier served from now on. Like
+// any payload, a multiplier of zero leaves the speed of synchronized
+// TimeKeepers unchanged.
+func (s *Server) Set(sim time.Time, multiplier float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.simTime = sim
+	s.multiplier = multiplier
+}
+
+// serve writes the current payload
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	payload := struct {
+		SimulatedTime string  `json:"simulated_time"`
+		Multiplier    float64 `json:"multiplier"`
+	}{
+		SimulatedTime: s.simTime.Format(time.RFC3339Nano),
+		Multiplier:    s.multiplier,
+	}
+	s.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payload)
+}
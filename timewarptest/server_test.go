@@ -0,0 +1,38 @@
+package timewarptest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kaneetz/timewarp"
+	"github.com/kaneetz/timewarp/timewarptest"
+)
+
+func TestServer(t *testing.T) {
+	sim := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	srv := timewarptest.NewServer(t, sim, 60)
+
+	tk := timewarp.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	// half the round trip at the served speed is added for latency
+	if d := tk.Now().Sub(sim); d < 0 || d > 60*tk.LastSyncRTT() {
+		t.Errorf("Now() after sync = %v, want about %v", tk.Now(), sim)
+	}
+	if m := tk.Multiplier(); m != 60 {
+		t.Errorf("Multiplier() after sync = %v, want 60", m)
+	}
+
+	later := sim.Add(time.Hour)
+	srv.Set(later, 0)
+	if err := tk.Synchronize(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if d := tk.Now().Sub(later); d < 0 || d > 60*tk.LastSyncRTT() {
+		t.Errorf("Now() after Set = %v, want about %v", tk.Now(), later)
+	}
+	if m := tk.Multiplier(); m != 60 {
+		t.Errorf("a zero multiplier changed the speed to %v", m)
+	}
+}
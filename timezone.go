@@ -0,0 +1,196 @@
+package timewarp
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// zoneDirs are the directories searched for the time zone database, in the
+// order time.LoadLocation tries them on Unix systems
+var zoneDirs = []string{
+	"/usr/share/zoneinfo/",
+	"/usr/share/lib/zoneinfo/",
+	"/usr/lib/locale/TZ/",
+	"/etc/zoneinfo/",
+}
+
+// loadZoneNames lists the zones of the first time zone database found
+var loadZoneNames = sync.OnceValue(func() []string {
+	dirs := zoneDirs
+	if dir := os.Getenv("ZONEINFO"); dir != "" {
+		dirs = append([]string{dir}, dirs...)
+	}
+	for _, dir := range dirs {
+		if names := zoneNamesIn(dir); len(names) > 0 {
+			return names
+		}
+	}
+	return nil
+})
+
+// zoneNamesIn lists the zone files below dir, skipping the posix and right
+// copies of the database and its index files
+func zoneNamesIn(dir string) []string {
+	var names []string
+	root := os.DirFS(dir)
+	fs.WalkDir(root, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path == "posix" || path == "right" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if isZoneFile(root, path) {
+			names = append(names, filepath.ToSlash(path))
+		}
+		return nil
+	})
+	slices.Sort(names)
+	return names
+}
+
+// isZoneFile reports whether the file at path holds compiled zone data
+func isZoneFile(root fs.FS, path string) bool {
+	f, err := root.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	n, _ := f.Read(magic)
+	return n == 4 && string(magic) == "TZif"
+}
+
+// Timezones returns the names of the time zones found in the time zone
+// database of the system in sorted order. It is empty when no database is
+// installed. Time zones embedded with the time/tzdata package can still be
+// loaded but are not listed.
+func Timezones() []string {
+	return slices.Clone(loadZoneNames())
+}
+
+// ValidTimezone reports whether New accepts name as a time zone
+func ValidTimezone(name string) bool {
+	if name == "" {
+		return false
+	}
+	if _, ok := parseFixedZone(name); ok {
+		return true
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// zoneName returns a name for loc that loadLocation reads back. A zone
+// missing from the database, like one from time.FixedZone or from parsing a
+// numeric offset, is written as its name followed by its offset such as
+// "+02:00".
+func zoneName(loc *time.Location) string {
+	name := loc.String()
+	if name != "" {
+		if _, err := time.LoadLocation(name); err == nil {
+			return name
+		}
+	}
+
+	_, offset := time.Now().In(loc).Zone()
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf("%s%c%02d:%02d", name, sign, offset/3600, offset/60%60)
+}
+
+// parseFixedZone reads a name written by zoneName for a fixed zone, an
+// optional name followed by an offset "±hh:mm"
+func parseFixedZone(name string) (*time.Location, bool) {
+	if len(name) < 6 {
+		return nil, false
+	}
+	prefix, offset := name[:len(name)-6], name[len(name)-6:]
+	if offset[0] != '+' && offset[0] != '-' || offset[3] != ':' {
+		return nil, false
+	}
+	hours, err := strconv.Atoi(offset[1:3])
+	if err != nil || hours > 23 {
+		return nil, false
+	}
+	minutes, err := strconv.Atoi(offset[4:])
+	if err != nil || minutes > 59 {
+		return nil, false
+	}
+
+	seconds := hours*3600 + minutes*60
+	if offset[0] == '-' {
+		seconds = -seconds
+	}
+	return time.FixedZone(prefix, seconds), true
+}
+
+// suggestTimezone returns the valid time zone name closest to a name that
+// failed to load, or "" if none is close enough
+func suggestTimezone(name string) string {
+	// the most common mistakes are spaces for underscores and the case
+	fixed := strings.ReplaceAll(strings.TrimSpace(name), " ", "_")
+	for _, candidate := range []string{fixed, titleZone(fixed), strings.ToUpper(fixed)} {
+		if candidate == name {
+			continue
+		}
+		if _, err := time.LoadLocation(candidate); err == nil {
+			return candidate
+		}
+	}
+
+	best, bestDistance := "", len(name)/3+1
+	target := strings.ToLower(fixed)
+	for _, zone := range loadZoneNames() {
+		if d := editDistance(target, strings.ToLower(zone)); d < bestDistance {
+			best, bestDistance = zone, d
+		}
+	}
+	return best
+}
+
+// titleZone capitalizes every word of a zone name, "america/new_york"
+// becomes "America/New_York"
+func titleZone(name string) string {
+	b := []byte(strings.ToLower(name))
+	for i := range b {
+		if i == 0 || b[i-1] == '/' || b[i-1] == '_' || b[i-1] == '-' {
+			b[i] = strings.ToUpper(string(b[i]))[0]
+		}
+	}
+	return string(b)
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
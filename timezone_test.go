@@ -0,0 +1,55 @@
+package timewarp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTimezoneSuggestion(t *testing.T) {
+	tests := []struct {
+		zone, suggestion string
+		needsListing     bool
+	}{
+		{"America/New York", "America/New_York", false},
+		{"america/new_york", "America/New_York", false},
+		{"Europe/Lodnon", "Europe/London", true},
+	}
+	for _, tt := range tests {
+		if tt.needsListing && len(Timezones()) == 0 {
+			t.Logf("%q: no time zone database to search", tt.zone)
+			continue
+		}
+		_, err := New("2024-01-01", "00:00", tt.zone, 1)
+		if !errors.Is(err, ErrInvalidTimezone) {
+			t.Errorf("New(%q) error = %v, want ErrInvalidTimezone", tt.zone, err)
+			continue
+		}
+		if want := `did you mean "` + tt.suggestion + `"?`; !strings.Contains(err.Error(), want) {
+			t.Errorf("New(%q) error = %q, want it to contain %q", tt.zone, err, want)
+		}
+	}
+
+	if _, err := New("2024-01-01", "00:00", "America/New_York", 1); err != nil {
+		t.Errorf("New(America/New_York) = %v", err)
+	}
+}
+
+func TestValidTimezone(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"UTC", true},
+		{"Europe/Paris", true},
+		{"+05:30", true},
+		{"", false},
+		{"Europe/Pariss", false},
+		{"+25:00", false},
+	}
+	for _, tt := range tests {
+		if got := ValidTimezone(tt.name); got != tt.want {
+			t.Errorf("ValidTimezone(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package timewarp
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// syncTransport advertises the simulated clock on outgoing requests
+type syncTransport struct {
+	base http.RoundTripper
+	tk   *TimeKeeper
+}
+
+// SyncTransport returns a RoundTripper setting HeaderNow and
+// HeaderMultiplier on every request from the clock of tk before passing it
+// to base, or to http.DefaultTransport when base is nil. It is the client
+// side of Middleware with AllowTimeHeaders.
+func SyncTransport(base http.RoundTripper, tk *TimeKeeper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &syncTransport{base: base, tk: tk}
+}
+
+// RoundTrip sends a copy of req carrying the time headers, req itself is
+// left untouched as http.RoundTripper requires
+func (t *syncTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.tk.mutex.Lock()
+	now := t.tk.current()
+	multiplier := t.tk.multiplier
+	t.tk.mutex.Unlock()
+
+	req = req.Clone(req.Context())
+	req.Header.Set(HeaderNow, now.Format(time.RFC3339Nano))
+	req.Header.Set(HeaderMultiplier, strconv.FormatFloat(multiplier, 'g', -1, 64))
+	return t.base.RoundTrip(req)
+}
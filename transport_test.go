@@ -0,0 +1,41 @@
+package timewarp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSyncTransport(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 30, 0, 500, time.UTC)
+	tk := NewManual(now)
+	tk.SetMultiplier(2.5)
+
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: SyncTransport(nil, tk)}
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got, want := header.Get(HeaderNow), "2030-01-01T12:30:00.0000005Z"; got != want {
+		t.Errorf("%s = %q, want %q", HeaderNow, got, want)
+	}
+	if got := header.Get(HeaderMultiplier); got != "2.5" {
+		t.Errorf("%s = %q, want %q", HeaderMultiplier, got, "2.5")
+	}
+	if req.Header.Get(HeaderNow) != "" {
+		t.Error("SyncTransport modified the original request")
+	}
+}